@@ -0,0 +1,86 @@
+package clock
+
+import "time"
+
+// DefaultAutoAdvanceIdle is how long the mock clock must go without activity,
+// in real time, before AutoAdvance moves it forward, unless another duration
+// is given. It is long enough for goroutines to do quick real work, such as
+// I/O over a loopback connection, between uses of the clock.
+const DefaultAutoAdvanceIdle = 50 * time.Millisecond
+
+// AutoAdvance starts moving the mock clock forward automatically, like a
+// discrete-event simulator: whenever the goroutines using the clock are
+// blocked, such as on a mock Sleep, timer, ticker or context deadline, the
+// clock jumps to the next registered timer and executes it. Call the returned
+// function to stop advancing.
+//
+// The goroutines are considered blocked once no timer has been created,
+// reset or stopped, and no AfterFunc function has run, for idle of real time,
+// or DefaultAutoAdvanceIdle if idle is zero. A goroutine that does real work
+// for longer than that without using the clock, such as slow I/O while
+// waiting on a mock deadline, may see the clock move forward early, so idle
+// should be longer than any such work. Only activity on this mock clock
+// counts, so goroutines of other tests running in parallel don't hold it
+// back. While no timers are registered, it waits for one to be created
+// without polling.
+func (m *Mock) AutoAdvance(idle time.Duration) (stop func()) {
+	if idle <= 0 {
+		idle = DefaultAutoAdvanceIdle
+	}
+
+	m.mu.Lock()
+	if m.activity == nil {
+		m.activity = make(chan struct{}, 1)
+	}
+	activity := m.activity
+	m.mu.Unlock()
+
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		wait := time.After(idle)
+		for {
+			select {
+			case <-done:
+				return
+			case <-activity:
+				wait = time.After(idle)
+				continue
+			case <-wait:
+			}
+
+			// Step once the clock has been idle. If there is nothing to
+			// run yet, wait for activity without a deadline.
+			wait = nil
+			if m.idle() {
+				if _, ok := m.Step(); ok {
+					wait = time.After(idle)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// idle returns true if no AfterFunc functions are running.
+func (m *Mock) idle() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.callbacks == 0
+}
+
+// notifyActivity signals AutoAdvance that the clock is in use. m.mu MUST be
+// held when this method is called.
+func (m *Mock) notifyActivity() {
+	if m.activity == nil {
+		return
+	}
+	select {
+	case m.activity <- struct{}{}:
+	default:
+	}
+}
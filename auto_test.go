@@ -0,0 +1,109 @@
+package clock
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Ensure that an hour-long retry loop completes without advancing the clock manually.
+func TestMock_AutoAdvance(t *testing.T) {
+	clock := NewMock()
+	stop := clock.AutoAdvance(0)
+	defer stop()
+
+	start := clock.Now()
+	done := make(chan int)
+	go func() {
+		// Retry with exponential backoff, waiting with various mock primitives.
+		attempts := 0
+		for backoff := time.Second; backoff < time.Hour; backoff *= 2 {
+			attempts++
+			switch attempts % 3 {
+			case 0:
+				clock.Sleep(backoff)
+			case 1:
+				<-clock.Timer(backoff).C
+			case 2:
+				ctx, cancel := clock.WithTimeout(context.Background(), backoff)
+				<-ctx.Done()
+				cancel()
+			}
+		}
+		done <- attempts
+	}()
+
+	select {
+	case attempts := <-done:
+		if attempts != 12 {
+			t.Fatalf("unexpected attempts: %d", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("retry loop did not finish, now=%s", clock.Now())
+	}
+	if d := clock.Since(start); d != 4095*time.Second {
+		t.Fatalf("unexpected elapsed time: %s", d)
+	}
+}
+
+// Ensure that the clock stops advancing once stopped.
+func TestMock_AutoAdvance_Stop(t *testing.T) {
+	clock := NewMock()
+	timer := clock.Timer(time.Second)
+	stop := clock.AutoAdvance(time.Millisecond)
+	<-timer.C
+	stop()
+
+	clock.Timer(time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if now := clock.Now(); !now.Equal(time.Unix(1, 0)) {
+		t.Fatalf("clock advanced after stop: %s", now)
+	}
+}
+
+// Ensure that real work done while holding a mock deadline doesn't let the
+// clock jump past the deadline.
+func TestMock_AutoAdvance_RealWork(t *testing.T) {
+	clock := NewMock()
+	stop := clock.AutoAdvance(0)
+	defer stop()
+
+	start := clock.Now()
+	for i := 0; i < 5; i++ {
+		ctx, cancel := clock.WithTimeout(context.Background(), 30*time.Second)
+		time.Sleep(5 * time.Millisecond) // e.g. a request to a local server
+		err := ctx.Err()
+		cancel()
+		if err != nil {
+			t.Fatalf("deadline exceeded during real work: %v", err)
+		}
+	}
+	if now := clock.Now(); !now.Equal(start) {
+		t.Fatalf("clock advanced during real work: %s", now)
+	}
+}
+
+// Ensure that goroutines not using the clock don't hold it back.
+func TestMock_AutoAdvance_Busy(t *testing.T) {
+	var done int32
+	defer atomic.StoreInt32(&done, 1)
+	go func() {
+		for atomic.LoadInt32(&done) == 0 {
+			runtime.Gosched()
+		}
+	}()
+
+	clock := NewMock()
+	stop := clock.AutoAdvance(time.Millisecond)
+	defer stop()
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		clock.Sleep(time.Hour)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("advancing took too long: %s", d)
+	}
+}
@@ -1,8 +1,9 @@
 package clock
 
 import (
+	"container/heap"
 	"context"
-	"sort"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -27,6 +28,8 @@ type Clock interface {
 	Timer(d time.Duration) *Timer
 	WithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc)
 	WithTimeout(parent context.Context, t time.Duration) (context.Context, context.CancelFunc)
+	WithDeadlineCause(parent context.Context, d time.Time, cause error) (context.Context, context.CancelFunc)
+	WithTimeoutCause(parent context.Context, t time.Duration, cause error) (context.Context, context.CancelFunc)
 }
 
 // New returns an instance of a real-time clock.
@@ -71,21 +74,66 @@ func (c *clock) WithTimeout(parent context.Context, t time.Duration) (context.Co
 	return context.WithTimeout(parent, t)
 }
 
+func (c *clock) WithDeadlineCause(parent context.Context, d time.Time, cause error) (context.Context, context.CancelFunc) {
+	return context.WithDeadlineCause(parent, d, cause)
+}
+
+func (c *clock) WithTimeoutCause(parent context.Context, t time.Duration, cause error) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(parent, t, cause)
+}
+
 // Mock represents a mock clock that only moves forward programmically.
 // It can be preferable to a real-time clock when testing time-based functionality.
+//
+// Timers, tickers and sleepers fire in the order of their next tick time.
+// Those due at the same time fire in the order they were scheduled, where
+// creating a timer, resetting it and a ticker ticking each schedule anew.
 type Mock struct {
 	// mu protects all other fields in this struct, and the data that they
 	// point to.
 	mu sync.Mutex
 
-	now    time.Time   // current time
-	timers clockTimers // tickers & timers
+	now       time.Time      // current wall time, without a monotonic reading
+	start     time.Time      // wall time the mock clock was created at
+	jumped    time.Duration  // total wall clock steps made by Jump
+	timers    clockTimers    // tickers & timers, kept as a min-heap by next tick
+	settle    SettleStrategy // how to wait for goroutines woken by a tick
+	blockers  []*blocker     // goroutines waiting in BlockUntil
+	oneShots  int            // number of registered timers that aren't tickers
+	seq       uint64         // number of times timers have been scheduled
+	legacy    bool           // use timer semantics from before Go 1.23
+	monotonic bool           // stamp times with a monotonic clock reading
+	loc       *time.Location // location of the current time, if set
+
+	tickerPolicy  TickerPolicy  // default policy of new tickers
+	afterFuncMode AfterFuncMode // how AfterFunc functions are run
+	callbacks     int           // number of running AfterFunc functions
+	callbacksDone *sync.Cond    // signalled when callbacks drops to zero
+
+	rng     *rand.Rand    // randomizes timer order, if set
+	seed    int64         // seed of rng
+	epsilon time.Duration // timers due within epsilon are randomly ordered
+
+	contexts   map[*timerCtx]struct{} // live contexts with deadlines
+	contextSeq uint64                 // number of contexts created
+
+	activity chan struct{} // signals AutoAdvance that the clock is in use
 }
 
 // NewMock returns an instance of a mock clock.
-// The current time of the mock clock on initialization is the Unix epoch.
-func NewMock() *Mock {
-	return &Mock{now: time.Unix(0, 0)}
+// The current time of the mock clock on initialization is the Unix epoch,
+// unless changed by one of the options.
+func NewMock(opts ...MockOption) *Mock {
+	m := &Mock{now: time.Unix(0, 0)}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.loc != nil {
+		m.now = m.now.In(m.loc)
+	}
+	m.now = m.now.Round(0)
+	m.start = m.now
+	return m
 }
 
 // Add moves the current time of the mock clock forward by the specified duration.
@@ -108,13 +156,29 @@ func (m *Mock) Add(d time.Duration) {
 	m.now = t
 	m.mu.Unlock()
 
-	// Give a small buffer to make sure that other goroutines get handled.
-	gosched()
+	// Make sure that other goroutines get handled.
+	m.settleGoroutines()
 }
 
 // Set sets the current time of the mock clock to a specific one.
 // This should only be called from a single goroutine at a time.
+//
+// If t is before the current time, Set steps the clock backward like Jump.
 func (m *Mock) Set(t time.Time) {
+	if m.loc != nil {
+		t = t.In(m.loc)
+	}
+	t = t.Round(0)
+
+	m.mu.Lock()
+	backward := t.Before(m.now)
+	d := t.Sub(m.now)
+	m.mu.Unlock()
+	if backward {
+		m.Jump(d)
+		return
+	}
+
 	// Continue to execute timers until there are no more before the new time.
 	for {
 		if !m.runNextTimer(t) {
@@ -127,8 +191,52 @@ func (m *Mock) Set(t time.Time) {
 	m.now = t
 	m.mu.Unlock()
 
-	// Give a small buffer to make sure that other goroutines get handled.
-	gosched()
+	// Make sure that other goroutines get handled.
+	m.settleGoroutines()
+}
+
+// Jump steps the wall clock of the mock clock by d, which may be negative,
+// without any time elapsing. This simulates the system clock being stepped,
+// e.g. by NTP. This should only be called from a single goroutine at a time.
+//
+// Timers, tickers, sleepers and WithTimeout contexts wait for a duration, so
+// they keep the remaining duration they had before the jump. Contexts created
+// by WithDeadline wait for a wall clock time, so they see the jump and expire
+// if their deadline is no longer in the future.
+func (m *Mock) Jump(d time.Duration) {
+	m.mu.Lock()
+	m.now = m.now.Add(d)
+	m.jumped += d
+	for _, t := range m.timers {
+		t.jump(d)
+	}
+	heap.Init(&m.timers)
+	now := m.now
+	m.mu.Unlock()
+
+	// Execute the wall clock timers that are now due.
+	for {
+		if !m.runNextTimer(now) {
+			break
+		}
+	}
+
+	// Make sure that other goroutines get handled.
+	m.settleGoroutines()
+}
+
+// SetWall sets the wall clock of the mock clock to t without any time
+// elapsing, so monotonic clock readings are unaffected. See Jump and
+// WithMonotonic.
+func (m *Mock) SetWall(t time.Time) {
+	if m.loc != nil {
+		t = t.In(m.loc)
+	}
+
+	m.mu.Lock()
+	d := t.Round(0).Sub(m.now)
+	m.mu.Unlock()
+	m.Jump(d)
 }
 
 // WaitForAllTimers sets the clock until all timers are expired
@@ -141,53 +249,178 @@ func (m *Mock) WaitForAllTimers() time.Time {
 			return m.Now()
 		}
 
-		sort.Sort(m.timers)
-		next := m.timers[len(m.timers)-1].Next()
+		// The heap only orders the earliest timer, so scan for the latest.
+		next := m.timers[0].Next()
+		for _, t := range m.timers[1:] {
+			if t.Next().After(next) {
+				next = t.Next()
+			}
+		}
 		m.mu.Unlock()
 		m.Set(next)
 	}
 }
 
+// BlockUntil blocks until at least n timers, tickers or sleepers are
+// registered on the mock clock. This can be used to make sure that the code
+// under test is waiting on the clock before moving time forward.
+func (m *Mock) BlockUntil(n int) {
+	_ = m.BlockUntilContext(context.Background(), n)
+}
+
+// BlockUntilContext is like BlockUntil but returns the context's error if
+// the context is done before n timers, tickers or sleepers are registered.
+func (m *Mock) BlockUntilContext(ctx context.Context, n int) error {
+	m.mu.Lock()
+	if len(m.timers) >= n {
+		m.mu.Unlock()
+		return nil
+	}
+	b := &blocker{n: n, ch: make(chan struct{})}
+	m.blockers = append(m.blockers, b)
+	m.mu.Unlock()
+
+	select {
+	case <-b.ch:
+		return nil
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, other := range m.blockers {
+		if other == b {
+			m.blockers = append(m.blockers[:i], m.blockers[i+1:]...)
+			break
+		}
+	}
+	return ctx.Err()
+}
+
+// notifyBlockers releases any goroutines in BlockUntil whose condition has
+// been met. m.mu MUST be held when this method is called.
+func (m *Mock) notifyBlockers() {
+	blockers := m.blockers[:0]
+	for _, b := range m.blockers {
+		if len(m.timers) >= b.n {
+			close(b.ch)
+		} else {
+			blockers = append(blockers, b)
+		}
+	}
+	for i := len(blockers); i < len(m.blockers); i++ {
+		m.blockers[i] = nil
+	}
+	m.blockers = blockers
+}
+
+// blocker represents a goroutine waiting in BlockUntil.
+type blocker struct {
+	n  int           // number of registered timers to wait for
+	ch chan struct{} // closed once n timers are registered
+}
+
+// Step moves the current time forward to the next registered timer and
+// executes exactly that timer. Returns a description of the timer as it was
+// before it fired, or false if no timers are registered.
+func (m *Mock) Step() (PendingTimer, bool) {
+	return m.stepTimer(maxTime, true)
+}
+
+// RunUntilIdle executes timers in chronological order until only tickers
+// remain or the next timer is more than limit after the current time. If the
+// limit is reached, the current time is moved to the limit. Returns the number
+// of timers and ticks executed.
+func (m *Mock) RunUntilIdle(limit time.Duration) int {
+	m.mu.Lock()
+	max := m.now.Add(limit)
+	m.mu.Unlock()
+
+	var n int
+	for {
+		m.mu.Lock()
+		idle := m.oneShots == 0
+		m.mu.Unlock()
+		if idle {
+			return n
+		}
+
+		if _, ok := m.stepTimer(max, false); !ok {
+			break
+		}
+		n++
+	}
+
+	// Ensure that we end with the limit.
+	m.mu.Lock()
+	m.now = max
+	m.mu.Unlock()
+	m.settleGoroutines()
+	return n
+}
+
 // runNextTimer executes the next timer in chronological order and moves the
 // current time to the timer's next tick time. The next time is not executed if
 // its next time is after the max time. Returns true if a timer was executed.
 func (m *Mock) runNextTimer(max time.Time) bool {
-	m.mu.Lock()
+	_, ok := m.stepTimer(max, false)
+	return ok
+}
 
-	// Sort timers by time.
-	sort.Sort(m.timers)
+// stepTimer is like runNextTimer but also returns a description of the
+// executed timer if describe is true.
+func (m *Mock) stepTimer(max time.Time, describe bool) (p PendingTimer, ok bool) {
+	m.mu.Lock()
 
 	// If we have no more timers then exit.
 	if len(m.timers) == 0 {
 		m.mu.Unlock()
-		return false
+		return p, false
 	}
 
 	// Retrieve next timer. Exit if next tick is after new time.
 	t := m.timers[0]
 	if t.Next().After(max) {
 		m.mu.Unlock()
-		return false
+		return p, false
+	}
+	if m.rng != nil {
+		t = m.randomTimer(max)
+	}
+	if describe {
+		p = t.pending()
 	}
 
-	// Move "now" forward and unlock clock.
-	m.now = t.Next()
+	// Move "now" forward, but never backward, and unlock clock.
+	if t.Next().After(m.now) {
+		m.now = t.Next()
+	}
 	now := m.now
 	m.mu.Unlock()
 
 	// Execute timer.
 	t.Tick(now)
-	return true
+	return p, true
 }
 
+// maxTime is the latest representable time, used when stepping to the next
+// timer regardless of when it fires.
+var maxTime = time.Unix(1<<63-62135596801, 999999999)
+
 // After waits for the duration to elapse and then sends the current time on the returned channel.
 func (m *Mock) After(d time.Duration) <-chan time.Time {
-	return m.Timer(d).C
+	return m.timer(d, KindTimer).C
 }
 
 // AfterFunc waits for the duration to elapse and then executes a function in its own goroutine.
 // A Timer is returned that can be stopped.
 func (m *Mock) AfterFunc(d time.Duration, f func()) *Timer {
+	return m.afterFunc(d, KindAfterFunc, f)
+}
+
+// afterFunc creates and registers a timer of the given kind that executes f
+// when it fires.
+func (m *Mock) afterFunc(d time.Duration, kind TimerKind, f func()) *Timer {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	ch := make(chan time.Time, 1)
@@ -196,25 +429,31 @@ func (m *Mock) AfterFunc(d time.Duration, f func()) *Timer {
 		fn:      f,
 		mock:    m,
 		next:    m.now.Add(d),
+		kind:    kind,
+		caller:  callers(),
 		stopped: false,
 	}
-	m.timers = append(m.timers, (*internalTimer)(t))
+	m.addClockTimer((*internalTimer)(t))
 	return t
 }
 
-// Now returns the current wall time on the mock clock.
+// Now returns the current wall time on the mock clock. If the mock clock was
+// created with WithMonotonic, the returned time also carries a monotonic clock
+// reading like those of time.Now.
 func (m *Mock) Now() time.Time {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.now
+	return m.stamp(m.now)
 }
 
-// Since returns time since `t` using the mock clock's wall time.
+// Since returns time since `t` using the mock clock. If `t` carries a
+// monotonic clock reading of the mock clock, that reading is used.
 func (m *Mock) Since(t time.Time) time.Duration {
 	return m.Now().Sub(t)
 }
 
-// Until returns time until `t` using the mock clock's wall time.
+// Until returns time until `t` using the mock clock. If `t` carries a
+// monotonic clock reading of the mock clock, that reading is used.
 func (m *Mock) Until(t time.Time) time.Duration {
 	return t.Sub(m.Now())
 }
@@ -222,7 +461,7 @@ func (m *Mock) Until(t time.Time) time.Duration {
 // Sleep pauses the goroutine for the given duration on the mock clock.
 // The clock must be moved forward in a separate goroutine.
 func (m *Mock) Sleep(d time.Duration) {
-	<-m.After(d)
+	<-m.timer(d, KindSleep).C
 }
 
 // Tick is a convenience function for Ticker().
@@ -237,18 +476,25 @@ func (m *Mock) Ticker(d time.Duration) *Ticker {
 	defer m.mu.Unlock()
 	ch := make(chan time.Time, 1)
 	t := &Ticker{
-		C:    ch,
-		c:    ch,
-		mock: m,
-		d:    d,
-		next: m.now.Add(d),
+		C:      ch,
+		c:      ch,
+		mock:   m,
+		d:      d,
+		next:   m.now.Add(d),
+		caller: callers(),
+		policy: m.tickerPolicy,
 	}
-	m.timers = append(m.timers, (*internalTicker)(t))
+	m.addClockTimer((*internalTicker)(t))
 	return t
 }
 
 // Timer creates a new instance of Timer.
 func (m *Mock) Timer(d time.Duration) *Timer {
+	return m.timer(d, KindTimer)
+}
+
+// timer creates and registers a channel-based timer of the given kind.
+func (m *Mock) timer(d time.Duration, kind TimerKind) *Timer {
 	m.mu.Lock()
 	ch := make(chan time.Time, 1)
 	t := &Timer{
@@ -256,62 +502,284 @@ func (m *Mock) Timer(d time.Duration) *Timer {
 		c:       ch,
 		mock:    m,
 		next:    m.now.Add(d),
+		kind:    kind,
+		caller:  callers(),
 		stopped: false,
 	}
-	m.timers = append(m.timers, (*internalTimer)(t))
+	m.addClockTimer((*internalTimer)(t))
 	now := m.now
 	m.mu.Unlock()
 	m.runNextTimer(now)
 	return t
 }
 
+// AfterFuncMode determines how a mock clock runs AfterFunc functions.
+type AfterFuncMode int
+
+const (
+	// AfterFuncAsync runs each function in its own goroutine, like the
+	// runtime does. This is the default mode. Use Mock.WaitForCallbacks to
+	// wait for the functions to return.
+	AfterFuncAsync AfterFuncMode = iota
+
+	// AfterFuncSync runs each function on the goroutine moving the clock
+	// forward, before Add or Set returns. Functions run in the order their
+	// timers are due, and in the order they were scheduled if due at the
+	// same time.
+	AfterFuncSync
+)
+
+// SetAfterFuncMode sets how the mock clock runs AfterFunc functions.
+func (m *Mock) SetAfterFuncMode(mode AfterFuncMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.afterFuncMode = mode
+}
+
+// WaitForCallbacks blocks until every AfterFunc function started by the mock
+// clock has returned.
+func (m *Mock) WaitForCallbacks() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.callbacks > 0 {
+		m.callbacksCond().Wait()
+	}
+}
+
+// startCallback returns a function that runs the AfterFunc function f
+// according to the AfterFunc mode. m.mu MUST be held when this method is
+// called, and MUST NOT be held when the returned function is called.
+func (m *Mock) startCallback(f func()) func() {
+	if m.afterFuncMode == AfterFuncSync {
+		return f
+	}
+
+	m.callbacks++
+	return func() {
+		go func() {
+			defer func() {
+				m.mu.Lock()
+				m.callbacks--
+				if m.callbacks == 0 {
+					m.callbacksCond().Broadcast()
+				}
+				m.notifyActivity()
+				m.mu.Unlock()
+			}()
+			f()
+		}()
+	}
+}
+
+// callbacksCond returns the condition signalled when the last running
+// AfterFunc function returns. m.mu MUST be held when this method is called.
+func (m *Mock) callbacksCond() *sync.Cond {
+	if m.callbacksDone == nil {
+		m.callbacksDone = sync.NewCond(&m.mu)
+	}
+	return m.callbacksDone
+}
+
+// TickerPolicy determines what a mock ticker does when a tick is due while the
+// previous tick hasn't been received yet, e.g. because Add moved the clock
+// over several periods at once.
+type TickerPolicy int
+
+const (
+	// TickerCoalesce drops the new tick, like the runtime does. This is the
+	// default policy.
+	TickerCoalesce TickerPolicy = iota
+
+	// TickerDeliverAll blocks until the previous tick has been received, so
+	// that every tick is delivered. Add and Set will not return if nothing
+	// receives from the ticker, until the ticker is stopped or reset.
+	TickerDeliverAll
+
+	// TickerSkipToLatest replaces the unreceived tick with the new one, so
+	// the receiver always sees the latest tick.
+	TickerSkipToLatest
+)
+
+// SetTickerPolicy sets the policy of tickers created afterwards. Existing
+// tickers can be changed with Ticker.SetPolicy.
+func (m *Mock) SetTickerPolicy(p TickerPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tickerPolicy = p
+}
+
+// SetLegacyTimers selects the semantics of mock timers and tickers. By
+// default they behave like those of Go 1.23 and later, where Stop and Reset
+// discard any value sent to the channel but not yet received. If legacy is
+// true, such stale values are kept, as they were before Go 1.23.
+func (m *Mock) SetLegacyTimers(legacy bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.legacy = legacy
+}
+
+// drainStale discards an unreceived value from a timer channel unless legacy
+// timer semantics are in use. Returns true if a value was discarded. m.mu
+// MUST be held when this method is called.
+func (m *Mock) drainStale(c chan time.Time) bool {
+	if m.legacy {
+		return false
+	}
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// addClockTimer registers a timer in m.timers. m.mu MUST be held when this
+// method is called.
+func (m *Mock) addClockTimer(t clockTimer) {
+	if _, ok := t.(*internalTicker); !ok {
+		m.oneShots++
+	}
+	m.seq++
+	t.setSequence(m.seq)
+	heap.Push(&m.timers, t)
+	m.notifyBlockers()
+	m.notifyActivity()
+}
+
+// rescheduleClockTimer restores the heap ordering of m.timers after the next
+// tick time of a registered timer has changed. The timer is ordered after
+// all timers already scheduled for the same time. m.mu MUST be held when this
+// method is called.
+func (m *Mock) rescheduleClockTimer(t clockTimer) {
+	if i := t.heapIndex(); m.timers.contains(t, i) {
+		m.seq++
+		t.setSequence(m.seq)
+		heap.Fix(&m.timers, i)
+		m.notifyActivity()
+	}
+}
+
 // removeClockTimer removes a timer from m.timers. m.mu MUST be held
 // when this method is called.
 func (m *Mock) removeClockTimer(t clockTimer) {
-	for i, timer := range m.timers {
-		if timer == t {
-			copy(m.timers[i:], m.timers[i+1:])
-			m.timers[len(m.timers)-1] = nil
-			m.timers = m.timers[:len(m.timers)-1]
-			break
+	if i := t.heapIndex(); m.timers.contains(t, i) {
+		if _, ok := t.(*internalTicker); !ok {
+			m.oneShots--
 		}
+		heap.Remove(&m.timers, i)
+		m.notifyActivity()
 	}
-	sort.Sort(m.timers)
 }
 
 // clockTimer represents an object with an associated start time.
 type clockTimer interface {
 	Next() time.Time
 	Tick(time.Time)
+
+	// heapIndex and setHeapIndex track the position of the timer within
+	// clockTimers so that it can be fixed or removed in O(log n).
+	heapIndex() int
+	setHeapIndex(i int)
+
+	// sequence and setSequence track the order in which timers were
+	// scheduled, so that timers with the same next time fire in that order.
+	sequence() uint64
+	setSequence(seq uint64)
+
+	// pending returns a description of the timer for Mock.Pending.
+	pending() PendingTimer
+
+	// jump adjusts the next tick time after the wall clock was stepped by d.
+	jump(d time.Duration)
 }
 
-// clockTimers represents a list of sortable timers.
+// clockTimers represents a min-heap of timers ordered by their next tick time.
+// It implements heap.Interface.
 type clockTimers []clockTimer
 
-func (a clockTimers) Len() int           { return len(a) }
-func (a clockTimers) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a clockTimers) Less(i, j int) bool { return a[i].Next().Before(a[j].Next()) }
+func (a clockTimers) Len() int { return len(a) }
+
+func (a clockTimers) Less(i, j int) bool {
+	if ti, tj := a[i].Next(), a[j].Next(); !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return a[i].sequence() < a[j].sequence()
+}
+
+func (a clockTimers) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+	a[i].setHeapIndex(i)
+	a[j].setHeapIndex(j)
+}
+
+func (a *clockTimers) Push(x interface{}) {
+	t := x.(clockTimer)
+	t.setHeapIndex(len(*a))
+	*a = append(*a, t)
+}
+
+func (a *clockTimers) Pop() interface{} {
+	old := *a
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.setHeapIndex(-1)
+	*a = old[:n-1]
+	return t
+}
+
+// contains reports whether t is registered at index i of the heap.
+func (a clockTimers) contains(t clockTimer, i int) bool {
+	return i >= 0 && i < len(a) && a[i] == t
+}
+
+// heapEntry holds the scheduling state of a mock timer or ticker.
+type heapEntry struct {
+	index int    // position in the mock's timer heap, -1 if not registered
+	seq   uint64 // order in which the timer was scheduled
+}
+
+func (e *heapEntry) heapIndex() int         { return e.index }
+func (e *heapEntry) setHeapIndex(i int)     { e.index = i }
+func (e *heapEntry) sequence() uint64       { return e.seq }
+func (e *heapEntry) setSequence(seq uint64) { e.seq = seq }
 
 // Timer represents a single event.
 // The current time will be sent on C, unless the timer was created by AfterFunc.
+//
+// Mock timers follow the semantics of Go 1.23 and later: once Stop or Reset
+// returns, no value sent before the call will be received from C. See
+// Mock.SetLegacyTimers for the earlier semantics.
 type Timer struct {
 	C       <-chan time.Time
 	c       chan time.Time
 	timer   *time.Timer // realtime impl, if set
+	impl    timerImpl   // derived clock impl, if set
 	next    time.Time   // next tick time
 	mock    *Mock       // mock clock, if set
 	fn      func()      // AfterFunc function, if set
+	kind    TimerKind   // what the mock timer was created for
+	caller  []uintptr   // call stack that created the mock timer
 	stopped bool        // True if stopped, false if running
+
+	heapEntry // scheduling state in the mock's timer heap
 }
 
 // Stop turns off the ticker.
 func (t *Timer) Stop() bool {
 	if t.timer != nil {
 		return t.timer.Stop()
+	} else if t.impl != nil {
+		return t.impl.Stop()
 	}
 
 	t.mock.mu.Lock()
 	registered := !t.stopped
+	if t.mock.drainStale(t.c) {
+		// An unreceived value means the timer hasn't expired as far as
+		// the receiver can tell.
+		registered = true
+	}
 	t.mock.removeClockTimer((*internalTimer)(t))
 	t.stopped = true
 	t.mock.mu.Unlock()
@@ -322,6 +790,8 @@ func (t *Timer) Stop() bool {
 func (t *Timer) Reset(d time.Duration) bool {
 	if t.timer != nil {
 		return t.timer.Reset(d)
+	} else if t.impl != nil {
+		return t.impl.Reset(d)
 	}
 
 	t.mock.mu.Lock()
@@ -329,51 +799,107 @@ func (t *Timer) Reset(d time.Duration) bool {
 	defer t.mock.mu.Unlock()
 
 	registered := !t.stopped
+	if t.mock.drainStale(t.c) {
+		registered = true
+	}
 	if t.stopped {
-		t.mock.timers = append(t.mock.timers, (*internalTimer)(t))
+		t.mock.addClockTimer((*internalTimer)(t))
+	} else {
+		t.mock.rescheduleClockTimer((*internalTimer)(t))
 	}
 
 	t.stopped = false
 	return registered
 }
 
+// timerImpl is implemented by the timers and tickers of clocks derived from
+// another clock, such as Scaled. The result of Reset is ignored for tickers.
+type timerImpl interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
 type internalTimer Timer
 
 func (t *internalTimer) Next() time.Time { return t.next }
+func (t *internalTimer) pending() PendingTimer {
+	return newPendingTimer(t.kind, t.next, 0, t.caller)
+}
+func (t *internalTimer) jump(d time.Duration) {
+	// Only WithDeadline timers are anchored to the wall clock.
+	if t.kind != KindDeadline {
+		t.next = t.next.Add(d)
+	}
+}
 func (t *internalTimer) Tick(now time.Time) {
-	// a gosched() after ticking, to allow any consequences of the
+	// settle after ticking, to allow any consequences of the
 	// tick to complete
-	defer gosched()
+	defer t.mock.settleGoroutines()
 
 	t.mock.mu.Lock()
-	if t.fn != nil {
-		// defer function execution until the lock is released, and
-		defer func() { go t.fn() }()
-	} else {
-		t.c <- now
+	// The timer may have been stopped or reset since it was scheduled.
+	if t.stopped || t.next.After(now) {
+		t.mock.mu.Unlock()
+		return
+	}
+
+	if t.fn == nil {
+		select {
+		case t.c <- t.mock.stamp(now):
+		default:
+		}
 	}
 	t.mock.removeClockTimer((*internalTimer)(t))
 	t.stopped = true
+
+	// Run the AfterFunc function once the lock is released.
+	var run func()
+	if t.fn != nil {
+		run = t.mock.startCallback(t.fn)
+	}
 	t.mock.mu.Unlock()
+
+	if run != nil {
+		run()
+	}
 }
 
 // Ticker holds a channel that receives "ticks" at regular intervals.
+//
+// Like Timer, mock tickers follow the semantics of Go 1.23 and later unless
+// Mock.SetLegacyTimers is used.
 type Ticker struct {
 	C       <-chan time.Time
 	c       chan time.Time
 	ticker  *time.Ticker  // realtime impl, if set
+	impl    timerImpl     // derived clock impl, if set
 	next    time.Time     // next tick time
 	mock    *Mock         // mock clock, if set
 	d       time.Duration // time between ticks
+	caller  []uintptr     // call stack that created the mock ticker
+	policy  TickerPolicy  // what to do with ticks the receiver falls behind on
+	dropped uint64        // number of ticks dropped or replaced
 	stopped bool          // True if stopped, false if running
+
+	// A TickerDeliverAll tick waiting to be received is sent without
+	// holding the lock of the mock clock. abort interrupts the send, and
+	// sending is closed once the send has completed or given up.
+	abort   chan struct{}
+	sending chan struct{}
+
+	heapEntry // scheduling state in the mock's timer heap
 }
 
 // Stop turns off the ticker.
 func (t *Ticker) Stop() {
 	if t.ticker != nil {
 		t.ticker.Stop()
+	} else if t.impl != nil {
+		t.impl.Stop()
 	} else {
 		t.mock.mu.Lock()
+		(*internalTicker)(t).interrupt()
+		t.mock.drainStale(t.c)
 		t.mock.removeClockTimer((*internalTicker)(t))
 		t.stopped = true
 		t.mock.mu.Unlock()
@@ -385,36 +911,143 @@ func (t *Ticker) Reset(dur time.Duration) {
 	if t.ticker != nil {
 		t.ticker.Reset(dur)
 		return
+	} else if t.impl != nil {
+		t.impl.Reset(dur)
+		return
 	}
 
 	t.mock.mu.Lock()
 	defer t.mock.mu.Unlock()
+	(*internalTicker)(t).interrupt()
+
+	t.d = dur
+	t.next = t.mock.now.Add(dur)
+	t.mock.drainStale(t.c)
 
 	if t.stopped {
-		t.mock.timers = append(t.mock.timers, (*internalTicker)(t))
+		t.mock.addClockTimer((*internalTicker)(t))
 		t.stopped = false
+	} else {
+		t.mock.rescheduleClockTimer((*internalTicker)(t))
 	}
+}
 
-	t.d = dur
-	t.next = t.mock.now.Add(dur)
+// SetPolicy sets what a mock ticker does with a tick that is due while the
+// previous tick is still unreceived. It has no effect on other tickers.
+func (t *Ticker) SetPolicy(p TickerPolicy) {
+	if t.mock == nil {
+		return
+	}
+	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+	t.policy = p
+}
+
+// Dropped returns the number of ticks a mock ticker has dropped or replaced
+// because the receiver fell behind. It always returns zero for other tickers.
+func (t *Ticker) Dropped() uint64 {
+	if t.mock == nil {
+		return 0
+	}
+	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+	return t.dropped
 }
 
 type internalTicker Ticker
 
 func (t *internalTicker) Next() time.Time { return t.next }
+func (t *internalTicker) pending() PendingTimer {
+	return newPendingTimer(KindTicker, t.next, t.d, t.caller)
+}
+func (t *internalTicker) jump(d time.Duration) { t.next = t.next.Add(d) }
 func (t *internalTicker) Tick(now time.Time) {
-	select {
-	case t.c <- now:
-	default:
-	}
+	defer t.mock.settleGoroutines()
+
 	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+	// The ticker may have been stopped or reset since it was scheduled.
+	if t.stopped || t.next.After(now) {
+		return
+	}
+
+	if sent := t.send(now); !sent && t.policy == TickerDeliverAll {
+		// Wait for the previous tick to be received, without holding the
+		// lock so the receiver can stop or reset the ticker.
+		if !t.sendBlocking(now) {
+			return // interrupted by Stop or Reset
+		}
+		// The ticker may have been stopped or reset after the send.
+		if t.stopped || t.next.After(now) {
+			return
+		}
+	}
+
 	t.next = now.Add(t.d)
+	t.mock.rescheduleClockTimer(t)
+}
+
+// sendBlocking blocks until the tick at now is received or until the ticker
+// is stopped or reset. Returns true if the tick was received. t.mock.mu MUST
+// be held when this method is called. It is released while blocking.
+func (t *internalTicker) sendBlocking(now time.Time) bool {
+	abort, sending := make(chan struct{}), make(chan struct{})
+	t.abort, t.sending = abort, sending
+	c, v := t.c, t.mock.stamp(now)
 	t.mock.mu.Unlock()
-	gosched()
+
+	var sent bool
+	select {
+	case c <- v:
+		sent = true
+	case <-abort:
+	}
+	close(sending)
+
+	t.mock.mu.Lock()
+	if t.sending == sending {
+		t.abort, t.sending = nil, nil
+	}
+	return sent
+}
+
+// interrupt stops a blocked send of a TickerDeliverAll tick, if there is one,
+// and waits for it to give up. t.mock.mu MUST be held when this method is
+// called. It is released while waiting.
+func (t *internalTicker) interrupt() {
+	for t.abort != nil {
+		sending := t.sending
+		close(t.abort)
+		t.abort, t.sending = nil, nil
+		t.mock.mu.Unlock()
+		<-sending
+		t.mock.mu.Lock()
+	}
 }
 
-// Sleep momentarily so that other goroutines can process.
-func gosched() { time.Sleep(1 * time.Millisecond) }
+// send delivers a tick according to the ticker's policy. Returns false if
+// the tick was not delivered because the previous tick is still unreceived.
+// t.mock.mu MUST be held when this method is called.
+func (t *internalTicker) send(now time.Time) bool {
+	select {
+	case t.c <- t.mock.stamp(now):
+		return true
+	default:
+	}
+
+	switch t.policy {
+	case TickerCoalesce:
+		t.dropped++
+	case TickerSkipToLatest:
+		select {
+		case <-t.c:
+		default:
+		}
+		t.c <- t.mock.stamp(now)
+		t.dropped++
+	}
+	return false
+}
 
 var (
 	// type checking
@@ -1,7 +1,9 @@
 package clock
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"os"
 	"sync"
 	"sync/atomic"
@@ -310,6 +312,163 @@ func TestMock_AfterFunc_Stop(t *testing.T) {
 	gosched()
 }
 
+// Ensure that synchronous AfterFunc functions run in order before Add returns.
+func TestMock_AfterFunc_Sync(t *testing.T) {
+	clock := NewMock(WithAfterFuncMode(AfterFuncSync), WithSettleStrategy(SettleNone))
+
+	var order []string
+	record := func(name string, at int64) func() {
+		return func() {
+			if now := clock.Now(); !now.Equal(time.Unix(at, 0)) {
+				t.Errorf("%s: unexpected time: %s", name, now)
+			}
+			order = append(order, name)
+		}
+	}
+	clock.AfterFunc(3*time.Second, record("c", 3))
+	clock.AfterFunc(1*time.Second, record("a", 1))
+	clock.AfterFunc(2*time.Second, record("b1", 2))
+	clock.AfterFunc(2*time.Second, record("b2", 2))
+
+	ctx, cancel := clock.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	clock.Add(5 * time.Second)
+	if got := fmt.Sprint(order); got != "[a b1 b2 c]" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Fatalf("unexpected context error: %v", ctx.Err())
+	}
+}
+
+// Ensure that WaitForCallbacks waits for asynchronous AfterFunc functions.
+func TestMock_WaitForCallbacks(t *testing.T) {
+	clock := NewMock(WithSettleStrategy(SettleNone))
+
+	var n counter
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		clock.AfterFunc(time.Second, func() {
+			<-release
+			n.incr()
+		})
+	}
+	clock.Add(time.Second)
+
+	close(release)
+	clock.WaitForCallbacks()
+	if got := n.get(); got != 3 {
+		t.Fatalf("expected 3 callbacks to have run, got %d", got)
+	}
+}
+
+// Ensure that timers due at the same time fire in the order they were scheduled.
+func TestMock_Timer_FIFO(t *testing.T) {
+	clock := NewMock(WithAfterFuncMode(AfterFuncSync), WithSettleStrategy(SettleNone))
+
+	var order []string
+	a := clock.AfterFunc(time.Second, func() { order = append(order, "a") })
+	clock.AfterFunc(time.Second, func() { order = append(order, "b") })
+	clock.AfterFunc(time.Second, func() { order = append(order, "c") })
+
+	// Resetting a timer schedules it after the others.
+	a.Reset(time.Second)
+
+	clock.Add(time.Second)
+	if got := fmt.Sprint(order); got != "[b c a]" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+
+	// A ticker's next tick is scheduled when it ticks, so it fires after
+	// a timer created earlier for the same time.
+	ticker := clock.Ticker(1 * time.Second)
+	defer ticker.Stop()
+	clock.Timer(2 * time.Second)
+	for i, want := range []TimerKind{KindTicker, KindTimer, KindTicker} {
+		if p, ok := clock.Step(); !ok || p.Kind != want {
+			t.Fatalf("%d: expected %s, got %s", i, want, p.Kind)
+		}
+	}
+}
+
+// Ensure that setting the time backward keeps the remaining duration of timers.
+func TestMock_Set_Backward(t *testing.T) {
+	clock := NewMock(WithStart(time.Unix(100, 0)))
+	timer := clock.Timer(10 * time.Second)
+	ticker := clock.Ticker(4 * time.Second)
+	defer ticker.Stop()
+
+	clock.Set(time.Unix(50, 0))
+	if now := clock.Now(); !now.Equal(time.Unix(50, 0)) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+
+	clock.Add(9 * time.Second)
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired early")
+	default:
+	}
+	if v := <-ticker.C; !v.Equal(time.Unix(54, 0)) {
+		t.Fatalf("unexpected tick: %s", v)
+	}
+
+	clock.Add(1 * time.Second)
+	if v := <-timer.C; !v.Equal(time.Unix(60, 0)) {
+		t.Fatalf("unexpected timer value: %s", v)
+	}
+}
+
+// Ensure that only WithDeadline contexts see a wall clock jump.
+func TestMock_Jump(t *testing.T) {
+	clock := NewMock()
+	deadline, cancel := clock.WithDeadline(context.Background(), clock.Now().Add(10*time.Second))
+	defer cancel()
+	timeout, cancel := clock.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	timer := clock.Timer(10 * time.Second)
+
+	clock.Jump(20 * time.Second)
+	if now := clock.Now(); !now.Equal(time.Unix(20, 0)) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+	if deadline.Err() != context.DeadlineExceeded {
+		t.Fatal("expected WithDeadline context to expire")
+	}
+	if timeout.Err() != nil {
+		t.Fatal("expected WithTimeout context to keep its remaining time")
+	}
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired after jump")
+	default:
+	}
+
+	clock.Add(10 * time.Second)
+	if timeout.Err() != context.DeadlineExceeded {
+		t.Fatal("expected WithTimeout context to expire")
+	}
+	<-timer.C
+}
+
+// Ensure that a WithDeadline context waits longer after a backward jump.
+func TestMock_Jump_Backward(t *testing.T) {
+	clock := NewMock()
+	ctx, cancel := clock.WithDeadline(context.Background(), clock.Now().Add(10*time.Second))
+	defer cancel()
+
+	clock.Jump(-5 * time.Second)
+	clock.Add(10 * time.Second)
+	if ctx.Err() != nil {
+		t.Fatal("context expired early")
+	}
+	clock.Add(5 * time.Second)
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Fatal("expected context to expire")
+	}
+}
+
 // Ensure that the mock's current time can be changed.
 func TestMock_Now(t *testing.T) {
 	clock := NewMock()
@@ -551,6 +710,92 @@ func TestMock_Ticker_Stop_Reset(t *testing.T) {
 	}
 }
 
+// Ensure that unreceived ticks are dropped and counted by default.
+func TestMock_Ticker_Coalesce(t *testing.T) {
+	clock := NewMock()
+	ticker := clock.Ticker(1 * time.Second)
+	defer ticker.Stop()
+
+	clock.Add(10 * time.Second)
+	if n := ticker.Dropped(); n != 9 {
+		t.Fatalf("expected 9 dropped ticks, got %d", n)
+	}
+	if v := <-ticker.C; !v.Equal(time.Unix(1, 0)) {
+		t.Fatalf("expected first tick, got %s", v)
+	}
+}
+
+// Ensure that unreceived ticks are replaced by the latest tick.
+func TestMock_Ticker_SkipToLatest(t *testing.T) {
+	clock := NewMock(WithTickerPolicy(TickerSkipToLatest))
+	ticker := clock.Ticker(1 * time.Second)
+	defer ticker.Stop()
+
+	clock.Add(10 * time.Second)
+	if n := ticker.Dropped(); n != 9 {
+		t.Fatalf("expected 9 dropped ticks, got %d", n)
+	}
+	if v := <-ticker.C; !v.Equal(time.Unix(10, 0)) {
+		t.Fatalf("expected latest tick, got %s", v)
+	}
+}
+
+// Ensure that every tick is delivered to a slow receiver.
+func TestMock_Ticker_DeliverAll(t *testing.T) {
+	clock := NewMock()
+	ticker := clock.Ticker(1 * time.Second)
+	defer ticker.Stop()
+	ticker.SetPolicy(TickerDeliverAll)
+
+	ticks := make(chan time.Time, 10)
+	go func() {
+		for v := range ticker.C {
+			time.Sleep(time.Millisecond)
+			ticks <- v
+		}
+	}()
+
+	clock.Add(10 * time.Second)
+	for i := 1; i <= 10; i++ {
+		if v := <-ticks; !v.Equal(time.Unix(int64(i), 0)) {
+			t.Fatalf("expected tick %d, got %s", i, v)
+		}
+	}
+	if n := ticker.Dropped(); n != 0 {
+		t.Fatalf("expected no dropped ticks, got %d", n)
+	}
+}
+
+// Ensure that stopping a ticker releases Add blocked on delivering a tick.
+func TestMock_Ticker_DeliverAll_Stop(t *testing.T) {
+	clock := NewMock()
+	ticker := clock.Ticker(1 * time.Second)
+	ticker.SetPolicy(TickerDeliverAll)
+
+	done := make(chan struct{})
+	go func() {
+		clock.Add(10 * time.Second)
+		close(done)
+	}()
+	<-ticker.C
+
+	// Wait for the third tick to block on the unreceived second one.
+	for clock.Now().Before(time.Unix(3, 0)) {
+		gosched()
+	}
+	ticker.Stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Add did not return after the ticker was stopped")
+	}
+	select {
+	case v := <-ticker.C:
+		t.Fatalf("unexpected tick after stop: %s", v)
+	default:
+	}
+}
+
 // Ensure that multiple tickers can be used together.
 func TestMock_Ticker_Multi(t *testing.T) {
 	var n int32
@@ -579,6 +824,184 @@ func TestMock_Ticker_Multi(t *testing.T) {
 	}
 }
 
+// Ensure that BlockUntil waits for sleepers to register.
+func TestMock_BlockUntil(t *testing.T) {
+	var n int32
+	clock := NewMock()
+
+	for i := 0; i < 3; i++ {
+		go func() {
+			clock.Sleep(time.Second)
+			atomic.AddInt32(&n, 1)
+		}()
+	}
+
+	clock.BlockUntil(3)
+	clock.Add(time.Second)
+	if got := atomic.LoadInt32(&n); got != 3 {
+		t.Fatalf("expected 3, got %d", got)
+	}
+
+	// Nothing is registered anymore, so only zero is satisfied immediately.
+	clock.BlockUntil(0)
+}
+
+// Ensure that BlockUntilContext returns once its context is done.
+func TestMock_BlockUntilContext(t *testing.T) {
+	clock := NewMock()
+	clock.Timer(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := clock.BlockUntilContext(ctx, 2); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clock.blockers) != 0 {
+		t.Fatalf("expected blocker to be removed, got %d", len(clock.blockers))
+	}
+
+	if err := clock.BlockUntilContext(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure that Step executes one timer at a time in chronological order.
+func TestMock_Step(t *testing.T) {
+	clock := NewMock()
+	if _, ok := clock.Step(); ok {
+		t.Fatal("expected no timer to step")
+	}
+
+	clock.AfterFunc(3*time.Second, func() {})
+	timer := clock.Timer(1 * time.Second)
+	ticker := clock.Ticker(2 * time.Second)
+	defer ticker.Stop()
+
+	for i, want := range []struct {
+		kind TimerKind
+		now  int64
+	}{
+		{KindTimer, 1},
+		{KindTicker, 2},
+		{KindAfterFunc, 3},
+		{KindTicker, 4},
+	} {
+		p, ok := clock.Step()
+		if !ok {
+			t.Fatalf("%d: expected a timer to step", i)
+		} else if p.Kind != want.kind {
+			t.Fatalf("%d: expected %s, got %s", i, want.kind, p.Kind)
+		} else if now := clock.Now(); !now.Equal(time.Unix(want.now, 0)) || !p.Next.Equal(now) {
+			t.Fatalf("%d: unexpected time: now=%s next=%s", i, now, p.Next)
+		}
+	}
+
+	select {
+	case <-timer.C:
+	default:
+		t.Fatal("expected timer to have fired")
+	}
+}
+
+// Ensure that RunUntilIdle runs until only tickers remain.
+func TestMock_RunUntilIdle(t *testing.T) {
+	clock := NewMock()
+	ticker := clock.Ticker(1 * time.Second)
+	defer ticker.Stop()
+
+	var fired counter
+	clock.AfterFunc(10500*time.Millisecond, func() {
+		clock.AfterFunc(5*time.Second, fired.incr)
+	})
+
+	if n := clock.RunUntilIdle(time.Hour); n != 17 {
+		t.Fatalf("expected 17 timers to run, got %d", n)
+	} else if fired.get() != 1 {
+		t.Fatal("expected chained AfterFunc to run")
+	} else if now := clock.Now(); !now.Equal(time.Unix(15, 5e8)) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+}
+
+// Ensure that RunUntilIdle stops at its limit.
+func TestMock_RunUntilIdle_Limit(t *testing.T) {
+	clock := NewMock()
+	ticker := clock.Ticker(1 * time.Second)
+	defer ticker.Stop()
+	clock.AfterFunc(10*time.Second, func() {})
+
+	if n := clock.RunUntilIdle(1500 * time.Millisecond); n != 1 {
+		t.Fatalf("expected 1 tick, got %d", n)
+	} else if now := clock.Now(); !now.Equal(time.Unix(1, 5e8)) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+}
+
+// Ensure that mock timers and tickers behave like the runtime's when values
+// are left unreceived across Stop and Reset.
+func TestMock_TimerSemantics_Conformance(t *testing.T) {
+	// Before Go 1.23, or with GODEBUG=asynctimerchan=1, timer channels are
+	// buffered and may hold stale values.
+	legacy := cap(time.NewTimer(time.Hour).C) != 0
+
+	run := func(c Clock, wait func(d time.Duration)) (results []bool) {
+		stale := func(ch <-chan time.Time) bool {
+			select {
+			case <-ch:
+				return true
+			default:
+				return false
+			}
+		}
+
+		// Stop after the timer expired without being received.
+		timer := c.Timer(10 * time.Millisecond)
+		wait(20 * time.Millisecond)
+		results = append(results, timer.Stop(), stale(timer.C))
+
+		// Reset after the timer expired without being received.
+		timer = c.Timer(10 * time.Millisecond)
+		wait(20 * time.Millisecond)
+		results = append(results, timer.Reset(time.Hour), stale(timer.C), timer.Stop())
+
+		// Stop and reset a ticker with an unreceived tick.
+		ticker := c.Ticker(10 * time.Millisecond)
+		wait(15 * time.Millisecond)
+		ticker.Reset(time.Hour)
+		results = append(results, stale(ticker.C))
+		ticker.Reset(10 * time.Millisecond)
+		wait(15 * time.Millisecond)
+		ticker.Stop()
+		results = append(results, stale(ticker.C))
+		return results
+	}
+
+	want := run(New(), time.Sleep)
+
+	mock := NewMock()
+	mock.SetLegacyTimers(legacy)
+	if got := run(mock, mock.Add); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("mock (legacy=%v) results %v differ from real clock %v", legacy, got, want)
+	}
+}
+
+// Ensure that legacy timers keep stale values across Reset.
+func TestMock_SetLegacyTimers(t *testing.T) {
+	clock := NewMock()
+	clock.SetLegacyTimers(true)
+
+	timer := clock.Timer(time.Second)
+	clock.Add(time.Second)
+	if timer.Reset(time.Second) {
+		t.Fatal("expected expired timer to report inactive")
+	}
+	select {
+	case <-timer.C:
+	default:
+		t.Fatal("expected stale value")
+	}
+}
+
 func ExampleMock_After() {
 	// Create a new mock clock.
 	clock := NewMock()
@@ -785,5 +1208,104 @@ func TestMock_AfterRace(t *testing.T) {
 	}
 }
 
+// Ensure that stopping timers registered out of order only cancels those timers.
+func TestMock_AfterFunc_StopMany(t *testing.T) {
+	clock := NewMock()
+
+	var mu sync.Mutex
+	var fired []time.Duration
+	for _, i := range rand.New(rand.NewSource(0)).Perm(100) {
+		d := time.Duration(i+1) * time.Second
+		clock.AfterFunc(d, func() {
+			mu.Lock()
+			fired = append(fired, d)
+			mu.Unlock()
+		})
+	}
+
+	// Stop every third timer before it fires.
+	clock.mu.Lock()
+	var stop []*Timer
+	for _, ct := range clock.timers {
+		if it, ok := ct.(*internalTimer); ok && it.next.Unix()%3 == 0 {
+			stop = append(stop, (*Timer)(it))
+		}
+	}
+	clock.mu.Unlock()
+	for _, timer := range stop {
+		timer.Stop()
+	}
+
+	clock.Add(100 * time.Second)
+	gosched()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fired) != 67 {
+		t.Fatalf("expected 67 timers to fire, got %d", len(fired))
+	}
+	for _, d := range fired {
+		if d%(3*time.Second) == 0 {
+			t.Fatalf("stopped timer fired: %s", d)
+		}
+	}
+}
+
+// Benchmark registering and stopping many timers on a mock clock.
+func BenchmarkMock_AfterFunc_Stop(b *testing.B) {
+	const n = 50000
+	rng := rand.New(rand.NewSource(0))
+	clock := NewMock()
+	timers := make([]*Timer, n)
+	for i := 0; i < b.N; i++ {
+		for j := range timers {
+			d := time.Duration(rng.Int63n(int64(24 * time.Hour)))
+			timers[j] = clock.AfterFunc(d, func() {})
+		}
+		for _, j := range rng.Perm(n) {
+			timers[j].Stop()
+		}
+	}
+}
+
+// Benchmark resetting many registered timers on a mock clock.
+func BenchmarkMock_Timer_Reset(b *testing.B) {
+	const n = 50000
+	rng := rand.New(rand.NewSource(0))
+	clock := NewMock()
+	timers := make([]*Timer, n)
+	for j := range timers {
+		timers[j] = clock.AfterFunc(time.Hour, func() {})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, timer := range timers {
+			timer.Reset(time.Duration(rng.Int63n(int64(24 * time.Hour))))
+		}
+	}
+}
+
+// Benchmark simulating a day of virtual time across many timers and tickers.
+func BenchmarkMock_Add_Day(b *testing.B) {
+	const n = 50000
+	rng := rand.New(rand.NewSource(0))
+	for i := 0; i < b.N; i++ {
+		// Don't settle, so that only the scheduling of timers is measured.
+		clock := NewMock(WithSettleStrategy(SettleNone))
+		for j := 0; j < n; j++ {
+			d := time.Duration(rng.Int63n(int64(24 * time.Hour)))
+			if j%2 == 0 {
+				clock.Timer(d)
+			} else {
+				clock.AfterFunc(d, func() {})
+			}
+		}
+		for j := 0; j < 10; j++ {
+			clock.Ticker(time.Minute)
+		}
+		clock.Add(24 * time.Hour)
+	}
+}
+
 func warn(v ...interface{})              { fmt.Fprintln(os.Stderr, v...) }
 func warnf(msg string, v ...interface{}) { fmt.Fprintf(os.Stderr, msg+"\n", v...) }
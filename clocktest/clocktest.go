@@ -0,0 +1,399 @@
+// Package clocktest provides a conformance test suite for implementations of
+// the clock.Clock interface.
+//
+// The suite checks that an implementation behaves like the real-time clock
+// returned by clock.New(). It can be run against wrappers around the real
+// clock as well as against clocks that are moved forward programmatically,
+// such as clock.Mock:
+//
+//	func TestConformance(t *testing.T) {
+//		clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+//			c := NewLoggingClock(clock.New())
+//			return c, func(d time.Duration) { time.Sleep(d) }
+//		})
+//	}
+package clocktest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// Factory returns a new clock for a single test, along with a function that
+// moves the clock forward by d. For clocks that follow real time the function
+// should simply sleep for d.
+type Factory func(t *testing.T) (c clock.Clock, advance func(d time.Duration))
+
+const (
+	// unit is the base duration used by the suite. It is large enough that
+	// real-time clocks are not affected by scheduling delays.
+	unit = 20 * time.Millisecond
+
+	// step is how far the clock is advanced at a time while waiting for an
+	// event to occur.
+	step = unit / 4
+
+	// maxSteps bounds how many steps are taken while waiting for an event.
+	maxSteps = 100
+)
+
+// RunConformance runs the conformance suite as subtests of t, creating a new
+// clock with factory for each of them.
+func RunConformance(t *testing.T, factory Factory) {
+	for _, tt := range []struct {
+		name string
+		fn   func(t *testing.T, c clock.Clock, advance func(time.Duration))
+	}{
+		{"Now", testNow},
+		{"After", testAfter},
+		{"AfterFunc", testAfterFunc},
+		{"AfterFunc_Stop", testAfterFuncStop},
+		{"Sleep", testSleep},
+		{"Tick", testTick},
+		{"Ticker", testTicker},
+		{"Ticker_Stop_Reset", testTickerStopReset},
+		{"Timer", testTimer},
+		{"Timer_Stop", testTimerStop},
+		{"Timer_Reset", testTimerReset},
+		{"NegativeDuration", testNegativeDuration},
+		{"WithDeadline", testWithDeadline},
+		{"WithDeadline_Past", testWithDeadlinePast},
+		{"WithDeadline_Parent", testWithDeadlineParent},
+		{"WithDeadline_ParentCancel", testWithDeadlineParentCancel},
+		{"WithTimeout", testWithTimeout},
+		{"WithDeadlineCause", testWithDeadlineCause},
+		{"WithTimeoutCause", testWithTimeoutCause},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c, advance := factory(t)
+			tt.fn(t, c, advance)
+		})
+	}
+}
+
+func testNow(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	advance(unit)
+	if d := c.Since(start); d < unit {
+		t.Fatalf("Since()=%s after advancing %s", d, unit)
+	}
+	if d := c.Until(start.Add(10 * unit)); d > 9*unit {
+		t.Fatalf("Until()=%s after advancing %s", d, unit)
+	}
+	if now := c.Now(); now.Before(start.Add(unit)) {
+		t.Fatalf("Now()=%s, expected at least %s", now, start.Add(unit))
+	}
+}
+
+func testAfter(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	ch := c.After(2 * unit)
+	expectNone(t, ch)
+	v := waitTime(t, ch, advance)
+	if d := v.Sub(start); d < 2*unit {
+		t.Fatalf("After() sent %s after start, expected at least %s", d, 2*unit)
+	}
+}
+
+func testAfterFunc(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	ch := make(chan time.Duration, 1)
+	c.AfterFunc(2*unit, func() { ch <- c.Since(start) })
+	if d := waitDuration(t, ch, advance); d < 2*unit {
+		t.Fatalf("AfterFunc() ran %s after start, expected at least %s", d, 2*unit)
+	}
+}
+
+func testAfterFuncStop(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	ch := make(chan time.Duration, 1)
+	timer := c.AfterFunc(unit, func() { ch <- 0 })
+	if !timer.Stop() {
+		t.Fatal("Stop() on pending AfterFunc returned false")
+	}
+	advance(2 * unit)
+	select {
+	case <-ch:
+		t.Fatal("stopped AfterFunc ran")
+	default:
+	}
+}
+
+func testSleep(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	ch := make(chan time.Duration, 1)
+	go func() {
+		c.Sleep(2 * unit)
+		ch <- c.Since(start)
+	}()
+	if d := waitDuration(t, ch, advance); d < 2*unit {
+		t.Fatalf("Sleep() returned %s after start, expected at least %s", d, 2*unit)
+	}
+}
+
+func testTick(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	ch := c.Tick(unit)
+	a := waitTime(t, ch, advance)
+	b := waitTime(t, ch, advance)
+	if a.Sub(start) < unit || b.Sub(a) < unit/2 {
+		t.Fatalf("unexpected ticks at %s and %s after start", a.Sub(start), b.Sub(start))
+	}
+}
+
+func testTicker(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	ticker := c.Ticker(unit)
+	defer ticker.Stop()
+	a := waitTime(t, ticker.C, advance)
+	b := waitTime(t, ticker.C, advance)
+	if a.Sub(start) < unit || b.Sub(a) < unit/2 {
+		t.Fatalf("unexpected ticks at %s and %s after start", a.Sub(start), b.Sub(start))
+	}
+}
+
+func testTickerStopReset(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	ticker := c.Ticker(unit)
+	defer ticker.Stop()
+	waitTime(t, ticker.C, advance)
+
+	ticker.Stop()
+	advance(2 * unit)
+	expectNone(t, ticker.C)
+
+	start := c.Now()
+	ticker.Reset(2 * unit)
+	if d := waitTime(t, ticker.C, advance).Sub(start); d < 2*unit {
+		t.Fatalf("tick %s after Reset(), expected at least %s", d, 2*unit)
+	}
+}
+
+func testTimer(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	timer := c.Timer(2 * unit)
+	expectNone(t, timer.C)
+	if d := waitTime(t, timer.C, advance).Sub(start); d < 2*unit {
+		t.Fatalf("timer fired %s after start, expected at least %s", d, 2*unit)
+	}
+	if timer.Stop() {
+		t.Fatal("Stop() on expired timer returned true")
+	}
+}
+
+func testTimerStop(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	timer := c.Timer(unit)
+	if !timer.Stop() {
+		t.Fatal("Stop() on pending timer returned false")
+	}
+	if timer.Stop() {
+		t.Fatal("Stop() on stopped timer returned true")
+	}
+	advance(2 * unit)
+	expectNone(t, timer.C)
+}
+
+func testTimerReset(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	timer := c.Timer(unit)
+	if !timer.Reset(3 * unit) {
+		t.Fatal("Reset() on pending timer returned false")
+	}
+	advance(2 * unit)
+	expectNone(t, timer.C)
+	if d := waitTime(t, timer.C, advance).Sub(start); d < 3*unit {
+		t.Fatalf("timer fired %s after start, expected at least %s", d, 3*unit)
+	}
+
+	timer.Stop()
+	if timer.Reset(unit) {
+		t.Fatal("Reset() on stopped timer returned true")
+	}
+	waitTime(t, timer.C, advance)
+}
+
+func testNegativeDuration(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	waitTime(t, c.After(-unit), advance)
+	waitTime(t, c.Timer(-unit).C, advance)
+
+	ch := make(chan time.Duration, 1)
+	c.AfterFunc(-unit, func() { ch <- 0 })
+	waitDuration(t, ch, advance)
+
+	done := make(chan time.Duration, 1)
+	go func() {
+		c.Sleep(-unit)
+		done <- 0
+	}()
+	waitDuration(t, done, advance)
+}
+
+func testWithDeadline(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	deadline := c.Now().Add(2 * unit)
+	ctx, cancel := c.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+		t.Fatalf("Deadline()=%s, %v; expected %s", d, ok, deadline)
+	}
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("unexpected error before deadline: %v", err)
+	}
+	waitDone(t, ctx, advance)
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error after deadline: %v", err)
+	}
+}
+
+func testWithDeadlinePast(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	ctx, cancel := c.WithDeadline(context.Background(), c.Now().Add(-unit))
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("context with past deadline is not done")
+	}
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func testWithDeadlineParent(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	parentDeadline := c.Now().Add(2 * unit)
+	parent, cancelParent := c.WithDeadline(context.Background(), parentDeadline)
+	defer cancelParent()
+	ctx, cancel := c.WithDeadline(parent, parentDeadline.Add(time.Hour))
+	defer cancel()
+
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(parentDeadline) {
+		t.Fatalf("Deadline()=%s, %v; expected parent deadline %s", d, ok, parentDeadline)
+	}
+	waitDone(t, ctx, advance)
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error after parent deadline: %v", err)
+	}
+}
+
+func testWithDeadlineParentCancel(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := c.WithDeadline(parent, c.Now().Add(time.Hour))
+	defer cancel()
+
+	cancelParent()
+	waitDone(t, ctx, advance)
+	if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error after parent cancel: %v", err)
+	}
+}
+
+func testWithTimeout(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	start := c.Now()
+	ctx, cancel := c.WithTimeout(context.Background(), 2*unit)
+	defer cancel()
+
+	if d, ok := ctx.Deadline(); !ok || d.Sub(start) < 2*unit {
+		t.Fatalf("Deadline()=%s, %v; expected at least %s", d, ok, start.Add(2*unit))
+	}
+	waitDone(t, ctx, advance)
+	if d := c.Since(start); d < 2*unit {
+		t.Fatalf("context done %s after start, expected at least %s", d, 2*unit)
+	}
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error after timeout: %v", err)
+	}
+
+	// Cancelling the context afterwards must not change its error.
+	cancel()
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error after cancel: %v", err)
+	}
+}
+
+func testWithDeadlineCause(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	cause := errors.New("deadline cause")
+	ctx, cancel := c.WithDeadlineCause(context.Background(), c.Now().Add(2*unit), cause)
+	defer cancel()
+
+	if err := context.Cause(ctx); err != nil {
+		t.Fatalf("unexpected cause before deadline: %v", err)
+	}
+	waitDone(t, ctx, advance)
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error after deadline: %v", err)
+	}
+	if err := context.Cause(ctx); !errors.Is(err, cause) {
+		t.Fatalf("unexpected cause after deadline: %v", err)
+	}
+}
+
+func testWithTimeoutCause(t *testing.T, c clock.Clock, advance func(time.Duration)) {
+	cause := errors.New("timeout cause")
+	ctx, cancel := c.WithTimeoutCause(context.Background(), 2*unit, cause)
+	waitDone(t, ctx, advance)
+	if err := context.Cause(ctx); !errors.Is(err, cause) {
+		t.Fatalf("unexpected cause after timeout: %v", err)
+	}
+
+	// Cancelling the context afterwards must not change its cause.
+	cancel()
+	if err := context.Cause(ctx); !errors.Is(err, cause) {
+		t.Fatalf("unexpected cause after cancel: %v", err)
+	}
+}
+
+// expectNone fails the test if a value is ready to be received from ch.
+func expectNone(t *testing.T, ch <-chan time.Time) {
+	t.Helper()
+	select {
+	case v := <-ch:
+		t.Fatalf("unexpected value: %s", v)
+	default:
+	}
+}
+
+// waitTime advances the clock until a value is received from ch.
+func waitTime(t *testing.T, ch <-chan time.Time, advance func(time.Duration)) time.Time {
+	t.Helper()
+	for i := 0; i < maxSteps; i++ {
+		select {
+		case v := <-ch:
+			return v
+		default:
+			advance(step)
+		}
+	}
+	t.Fatal("timed out waiting for value")
+	return time.Time{}
+}
+
+// waitDuration advances the clock until a value is received from ch.
+func waitDuration(t *testing.T, ch <-chan time.Duration, advance func(time.Duration)) time.Duration {
+	t.Helper()
+	for i := 0; i < maxSteps; i++ {
+		select {
+		case v := <-ch:
+			return v
+		default:
+			advance(step)
+		}
+	}
+	t.Fatal("timed out waiting for value")
+	return 0
+}
+
+// waitDone advances the clock until ctx is done.
+func waitDone(t *testing.T, ctx context.Context, advance func(time.Duration)) {
+	t.Helper()
+	for i := 0; i < maxSteps; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			advance(step)
+		}
+	}
+	t.Fatal("timed out waiting for context to be done")
+}
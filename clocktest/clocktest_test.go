@@ -0,0 +1,73 @@
+package clocktest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/benbjohnson/clock/clocktest"
+)
+
+func TestConformance_Clock(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		return clock.New(), time.Sleep
+	})
+}
+
+func TestConformance_Mock(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		m := clock.NewMock()
+		return m, m.Add
+	})
+}
+
+func TestConformance_Scaled(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		return clock.NewScaled(clock.New(), 1, time.Time{}), time.Sleep
+	})
+}
+
+func TestConformance_Scaled_Mock(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		m := clock.NewMock()
+		c := clock.NewScaled(m, 2, time.Time{})
+		return c, func(d time.Duration) { m.Add(d / 2) }
+	})
+}
+
+func TestConformance_Offset(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		return clock.NewOffset(clock.New(), 30*24*time.Hour), time.Sleep
+	})
+}
+
+func TestConformance_Pausable(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		c := clock.NewPausable(clock.New())
+		c.Pause()
+		c.Resume()
+		return c, time.Sleep
+	})
+}
+
+func TestConformance_Hybrid(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		c := clock.NewHybrid(clock.New())
+		c.Freeze()
+		return c, func(d time.Duration) {
+			// Give goroutines woken by timers a chance to react, as
+			// Advance doesn't wait for them.
+			c.Advance(d)
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+func TestConformance_Drifting(t *testing.T) {
+	clocktest.RunConformance(t, func(t *testing.T) (clock.Clock, func(time.Duration)) {
+		m := clock.NewMock()
+		c := clock.NewDrifting(m, 1000, clock.WithCorrection(10*time.Millisecond, clock.CorrectionSlew))
+		t.Cleanup(c.Stop)
+		return c, m.Add
+	})
+}
@@ -3,84 +3,347 @@ package clock
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 )
 
+// clockKey is the context key for the clock carried by a context.
+type clockKey struct{}
+
+// WithClock returns a copy of ctx that carries c. Use FromContext to retrieve
+// it, e.g. in code that can't easily be passed a clock.
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// FromContext returns the clock carried by ctx, or a real-time clock if ctx
+// doesn't carry one. Contexts created by the WithDeadline and WithTimeout
+// methods of a mock clock, or of another clock of this package wrapping a
+// base clock, carry that clock, as do their children.
+func FromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	return New()
+}
+
 func (m *Mock) WithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
-	return m.WithDeadline(parent, m.Now().Add(timeout))
+	return m.withDeadline(parent, m.Now().Add(timeout), KindTimeout, nil)
 }
 
 func (m *Mock) WithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	return m.withDeadline(parent, deadline, KindDeadline, nil)
+}
+
+// WithTimeoutCause is like WithTimeout but sets the cause of the returned
+// context to cause when the timeout expires.
+func (m *Mock) WithTimeoutCause(parent context.Context, timeout time.Duration, cause error) (context.Context, context.CancelFunc) {
+	return m.withDeadline(parent, m.Now().Add(timeout), KindTimeout, cause)
+}
+
+// WithDeadlineCause is like WithDeadline but sets the cause of the returned
+// context to cause when the deadline is exceeded.
+func (m *Mock) WithDeadlineCause(parent context.Context, deadline time.Time, cause error) (context.Context, context.CancelFunc) {
+	return m.withDeadline(parent, deadline, KindDeadline, cause)
+}
+
+// withDeadline creates a context that is cancelled at deadline by a mock
+// timer of the given kind, and registers it until it is done.
+func (m *Mock) withDeadline(parent context.Context, deadline time.Time, kind TimerKind, cause error) (context.Context, context.CancelFunc) {
+	caller := callers()
+	ctx, cancel := withDeadline(m, parent, deadline, cause, func(d time.Duration, f func()) *Timer {
+		return m.afterFunc(d, kind, f)
+	})
+	ctx.kind, ctx.caller = kind, caller
+	m.addContext(ctx)
+	return ctx, cancel
+}
+
+// addContext registers ctx in m.contexts unless it is already done.
+func (m *Mock) addContext(ctx *timerCtx) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ctx.Lock()
+	err := ctx.err
+	ctx.Unlock()
+	if err != nil {
+		return
+	}
+	if m.contexts == nil {
+		m.contexts = make(map[*timerCtx]struct{})
+	}
+	m.contextSeq++
+	ctx.seq = m.contextSeq
+	m.contexts[ctx] = struct{}{}
+}
+
+// removeContext removes ctx from m.contexts.
+func (m *Mock) removeContext(ctx *timerCtx) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.contexts, ctx)
+}
+
+// withDeadline creates a context that is cancelled at deadline on clock c,
+// using afterFunc to schedule the cancellation. If cause is nil, the cause of
+// the context is context.DeadlineExceeded once the deadline is exceeded.
+//
+// If the parent has an earlier deadline, the context takes on the deadline of
+// the parent and is only cancelled along with it.
+func withDeadline(c Clock, parent context.Context, deadline time.Time, cause error, afterFunc func(time.Duration, func()) *Timer) (*timerCtx, context.CancelFunc) {
+	own := true
 	if cur, ok := parent.Deadline(); ok && cur.Before(deadline) {
 		// The current deadline is already sooner than the new one.
-		return context.WithCancel(parent)
+		deadline, own = cur, false
 	}
-	ctx := &timerCtx{clock: m, parent: parent, deadline: deadline, done: make(chan struct{})}
-	propagateCancel(parent, ctx)
-	dur := m.Until(deadline)
-	if dur <= 0 {
-		ctx.cancel(context.DeadlineExceeded) // deadline has already passed
-		return ctx, func() {}
+	ctx := &timerCtx{clock: c, parent: parent, deadline: deadline}
+	ctx.signal.done = make(chan struct{})
+	ctx.signal.cause, ctx.signal.cancelCause = context.WithCancelCause(context.Background())
+	ctx.inner, ctx.cancelInner = context.WithCancel(&ctx.signal)
+	cancel := func() { ctx.cancel(context.Canceled, nil) }
+
+	if err := parent.Err(); err != nil {
+		ctx.cancel(err, context.Cause(parent)) // parent is already canceled
+		return ctx, cancel
+	}
+	if own {
+		if c.Until(deadline) <= 0 {
+			ctx.cancel(context.DeadlineExceeded, cause) // deadline has already passed
+			return ctx, cancel
+		}
 	}
+
 	ctx.Lock()
 	defer ctx.Unlock()
-	if ctx.err == nil {
-		ctx.timer = m.AfterFunc(dur, func() {
-			ctx.cancel(context.DeadlineExceeded)
+
+	// Arrange for the context to be canceled along with its parent. This
+	// doesn't start a goroutine if the parent is a standard library or mock
+	// context, even behind context.WithValue, and cancels mock children
+	// before their parent is done.
+	if p, ok := parentTimerCtx(parent); ok {
+		ctx.stop = p.addChild(ctx)
+	} else {
+		ctx.stop = context.AfterFunc(parent, func() {
+			ctx.cancel(parent.Err(), context.Cause(parent))
 		})
 	}
-	return ctx, func() { ctx.cancel(context.Canceled) }
+	if own && ctx.err == nil {
+		ctx.timer = afterFunc(c.Until(deadline), func() {
+			ctx.cancel(context.DeadlineExceeded, cause)
+		})
+	}
+	return ctx, cancel
 }
 
-// propagateCancel arranges for child to be canceled when parent is.
-func propagateCancel(parent context.Context, child *timerCtx) {
-	if parent.Done() == nil {
-		return // parent is never canceled
+// parentTimerCtx returns the nearest context of parent created by withDeadline,
+// if parent is done along with it, like the standard library finds the nearest
+// cancelable context of a parent.
+func parentTimerCtx(parent context.Context) (*timerCtx, bool) {
+	p, ok := parent.Value(timerCtxKey{}).(*timerCtx)
+	if !ok || p.Done() != parent.Done() {
+		return nil, false
 	}
-	go func() {
-		select {
-		case <-parent.Done():
-			child.cancel(parent.Err())
-		case <-child.Done():
-		}
-	}()
+	return p, true
 }
 
+// timerCtxKey is the context key for the nearest timerCtx of a context.
+type timerCtxKey struct{}
+
+// timerCtx is a context with a deadline on a clock other than the real-time
+// clock. Together with its mock children it forms a tree like the contexts of
+// the standard library.
 type timerCtx struct {
 	sync.Mutex
 
 	clock    Clock
 	parent   context.Context
-	deadline time.Time
-	done     chan struct{}
+	deadline time.Time // deadline of the context or of its parent, if sooner
+
+	// inner is a standard library context that is done along with the
+	// context, with its error and cause. Sharing its done channel and
+	// returning it from Value lets the standard library cancel children
+	// without a goroutine, even behind context.WithValue. It is only
+	// cancelled through signal, as cancelInner would report context.Canceled.
+	inner       context.Context
+	cancelInner context.CancelFunc
+	signal      cancelSignal // parent of inner
+
+	kind   TimerKind // kind of mock context
+	caller []uintptr // call stack of the creator of a mock context
+	seq    uint64    // order a mock context was created in
 
-	err   error
-	timer *Timer
+	err      error
+	cause    error
+	timer    *Timer                 // cancels the context at its deadline, if set
+	stop     func() bool            // stops waiting for the parent to be canceled
+	children map[*timerCtx]struct{} // children cancelled along with the context
 }
 
-func (c *timerCtx) cancel(err error) {
+// addChild arranges for child to be canceled along with c, or cancels it if c
+// is already done. Returns a function that undoes the arrangement.
+func (c *timerCtx) addChild(child *timerCtx) (stop func() bool) {
 	c.Lock()
-	defer c.Unlock()
 	if c.err != nil {
+		err, cause := c.err, c.cause
+		c.Unlock()
+		go child.cancel(err, cause)
+		return func() bool { return false }
+	}
+	if c.children == nil {
+		c.children = make(map[*timerCtx]struct{})
+	}
+	c.children[child] = struct{}{}
+	c.Unlock()
+
+	return func() bool {
+		c.Lock()
+		defer c.Unlock()
+		_, ok := c.children[child]
+		delete(c.children, child)
+		return ok
+	}
+}
+
+// cancel cancels the context and its mock children with err. The cause of the
+// context is cause, or err if cause is nil.
+func (c *timerCtx) cancel(err, cause error) {
+	c.Lock()
+	if c.err != nil {
+		c.Unlock()
 		return // already canceled
 	}
-	c.err = err
-	close(c.done)
-	if c.timer != nil {
-		c.timer.Stop()
-		c.timer = nil
+	if cause == nil {
+		cause = err
+	}
+	c.err, c.cause = err, cause
+	timer, stop, children := c.timer, c.stop, c.children
+	c.timer, c.stop, c.children = nil, nil, nil
+	c.Unlock()
+
+	if timer != nil {
+		timer.Stop()
+	}
+	if stop != nil {
+		stop()
+	}
+	for child := range children {
+		child.cancel(err, cause)
+	}
+	if r, ok := c.clock.(contextRegistry); ok {
+		r.removeContext(c)
 	}
+	c.signal.fire(err, cause)
 }
 
-func (c *timerCtx) Deadline() (deadline time.Time, ok bool) { return c.deadline, true }
+// contextRegistry is implemented by clocks that keep track of their contexts
+// until they are done.
+type contextRegistry interface {
+	removeContext(ctx *timerCtx)
+}
 
-func (c *timerCtx) Done() <-chan struct{} { return c.done }
+// cancelSignal is a context that reports any error and cause to the
+// standard library context of a timerCtx, which can otherwise only be
+// cancelled with context.Canceled.
+type cancelSignal struct {
+	mu   sync.Mutex
+	err  error
+	done chan struct{}
+	f    func()
 
-func (c *timerCtx) Err() error { return c.err }
+	// cause is cancelled with the cause of the signal, so that
+	// context.Cause reports it to the children of the signal.
+	cause       context.Context
+	cancelCause context.CancelCauseFunc
+}
 
-func (c *timerCtx) Value(key interface{}) interface{} { return c.parent.Value(key) }
+// fire cancels the signal and calls the function registered by its child.
+func (s *cancelSignal) fire(err, cause error) {
+	s.cancelCause(cause)
+	s.mu.Lock()
+	s.err = err
+	f := s.f
+	s.mu.Unlock()
+	close(s.done)
+	if f != nil {
+		f()
+	}
+}
+
+// AfterFunc registers f to be called when the signal fires. The standard
+// library calls it once, to cancel the child of the signal.
+func (s *cancelSignal) AfterFunc(f func()) (stop func() bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.f = f
+	return func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		ok := s.f != nil
+		s.f = nil
+		return ok
+	}
+}
+
+func (s *cancelSignal) Deadline() (deadline time.Time, ok bool) { return time.Time{}, false }
+
+func (s *cancelSignal) Done() <-chan struct{} { return s.done }
+
+func (s *cancelSignal) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
 
+func (s *cancelSignal) Value(key interface{}) interface{} { return s.cause.Value(key) }
+
+func (c *timerCtx) Deadline() (deadline time.Time, ok bool) {
+	c.Lock()
+	defer c.Unlock()
+	return c.deadline, true
+}
+
+// shift moves the deadline of the context by d, after the time of its clock
+// was stepped by d without any time elapsing.
+func (c *timerCtx) shift(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.deadline = c.deadline.Add(d)
+}
+
+func (c *timerCtx) Done() <-chan struct{} { return c.inner.Done() }
+
+func (c *timerCtx) Err() error { return c.inner.Err() }
+
+// Value returns the value of the parent for key, except for the key looked up
+// by context.Cause, for which it returns the standard library context of the
+// context, and the key looked up by FromContext, for which it returns the
+// clock of the context.
+func (c *timerCtx) Value(key interface{}) interface{} {
+	if v := c.inner.Value(key); v != nil {
+		return v
+	}
+	switch key {
+	case clockKey{}:
+		return c.clock
+	case timerCtxKey{}:
+		return c
+	}
+	return c.parent.Value(key)
+}
+
+// String describes the context like the contexts of the standard library, with
+// the time remaining until the deadline on the clock of the context.
 func (c *timerCtx) String() string {
-	return fmt.Sprintf("clock.WithDeadline(%s [%s])", c.deadline, c.deadline.Sub(c.clock.Now()))
+	deadline, _ := c.Deadline()
+	return fmt.Sprintf("%s.clock.WithDeadline(%s [%s])", contextName(c.parent), deadline, deadline.Sub(c.clock.Now()))
+}
+
+// contextName returns the description of ctx given by its String method, or
+// its type if it doesn't have one.
+func contextName(ctx context.Context) string {
+	if s, ok := ctx.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return reflect.TypeOf(ctx).String()
 }
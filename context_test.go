@@ -3,6 +3,9 @@ package clock
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -97,3 +100,241 @@ func TestMock_WithTimeout(t *testing.T) {
 		t.Error("context is not cancelled when time is over")
 	}
 }
+
+// Ensure that WithDeadlineCause sets the cause when the deadline is exceeded.
+func TestMock_WithDeadlineCause(t *testing.T) {
+	errBudget := errors.New("request budget exceeded")
+	m := NewMock()
+	ctx, cancel := m.WithDeadlineCause(context.Background(), m.Now().Add(time.Second), errBudget)
+	defer cancel()
+	child, cancelChild := context.WithCancel(ctx)
+	defer cancelChild()
+
+	if err := context.Cause(ctx); err != nil {
+		t.Fatalf("unexpected cause before deadline: %v", err)
+	}
+	m.Add(time.Second)
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := context.Cause(ctx); !errors.Is(err, errBudget) {
+		t.Fatalf("unexpected cause: %v", err)
+	}
+
+	<-child.Done()
+	if err := child.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected child error: %v", err)
+	} else if err := context.Cause(child); !errors.Is(err, errBudget) {
+		t.Fatalf("unexpected child cause: %v", err)
+	}
+}
+
+// Ensure that the cause of WithTimeoutCause is the error when cancelled or
+// when the parent is cancelled.
+func TestMock_WithTimeoutCause_Cancel(t *testing.T) {
+	errBudget := errors.New("request budget exceeded")
+	errShutdown := errors.New("shutting down")
+	m := NewMock()
+
+	ctx, cancel := m.WithTimeoutCause(context.Background(), time.Second, errBudget)
+	cancel()
+	m.Add(time.Second)
+	if err := context.Cause(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected cause after cancel: %v", err)
+	}
+
+	parent, cancelParent := context.WithCancelCause(context.Background())
+	ctx, cancel = m.WithTimeoutCause(parent, time.Second, errBudget)
+	defer cancel()
+	cancelParent(errShutdown)
+	<-ctx.Done()
+	if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := context.Cause(ctx); !errors.Is(err, errShutdown) {
+		t.Fatalf("unexpected cause: %v", err)
+	}
+}
+
+// Ensure that mock contexts don't start goroutines to wait for their parents,
+// even behind context.WithValue, and stop their timers when their parents are
+// cancelled.
+func TestMock_WithDeadline_NoGoroutineLeak(t *testing.T) {
+	type key struct{}
+	const n = 1000
+	m := NewMock()
+	parent, cancel := context.WithCancel(context.Background())
+	before := runtime.NumGoroutine()
+
+	ctxs := make([]context.Context, 0, 5*n)
+	for i := 0; i < n; i++ {
+		ctx, _ := m.WithTimeout(parent, time.Hour)
+		nested, _ := m.WithTimeout(ctx, time.Minute)
+		child, cancelChild := context.WithCancel(nested)
+		defer cancelChild()
+		valued := context.WithValue(nested, key{}, i)
+		valuedNested, _ := m.WithTimeout(valued, time.Second)
+		valuedChild, cancelValuedChild := context.WithCancel(valued)
+		defer cancelValuedChild()
+		ctxs = append(ctxs, ctx, nested, child, valuedNested, valuedChild)
+	}
+	if after := runtime.NumGoroutine(); after > before+10 {
+		t.Fatalf("expected no goroutines per context, got %d more", after-before)
+	}
+
+	cancel()
+	for _, ctx := range ctxs {
+		<-ctx.Done()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Fatalf("unexpected error: %v", ctx.Err())
+		}
+	}
+	if p := m.Pending(); len(p) != 0 {
+		t.Fatalf("expected timers to be stopped, got %d", len(p))
+	}
+}
+
+// Ensure that a mock context nested in one with an earlier deadline takes on
+// the parent's deadline, error and cause without a timer of its own.
+func TestMock_WithDeadline_Nested(t *testing.T) {
+	errBudget := errors.New("request budget exceeded")
+	m := NewMock()
+	parent, cancelParent := m.WithTimeoutCause(context.Background(), time.Second, errBudget)
+	defer cancelParent()
+	middle, cancelMiddle := context.WithCancel(parent)
+	defer cancelMiddle()
+	valued, cancelValued := context.WithCancel(context.WithValue(parent, struct{}{}, 0))
+	defer cancelValued()
+	ctx, cancel := m.WithDeadline(middle, m.Now().Add(time.Minute))
+	defer cancel()
+	inner, cancelInner := m.WithTimeout(ctx, time.Hour)
+	defer cancelInner()
+
+	want, _ := parent.Deadline()
+	for _, c := range []context.Context{ctx, inner} {
+		if d, ok := c.Deadline(); !ok || !d.Equal(want) {
+			t.Fatalf("unexpected deadline: %s, %v", d, ok)
+		}
+	}
+	if p := m.Pending(); len(p) != 1 {
+		t.Fatalf("expected only the parent's timer, got %v", p)
+	}
+	if s, want := inner.(fmt.Stringer).String(), fmt.Sprintf(".clock.WithDeadline(%s [1s]).clock.WithDeadline(%s [1s])", want, want); !strings.HasSuffix(s, want) {
+		t.Fatalf("unexpected string: %s", s)
+	}
+	if s, want := parent.(fmt.Stringer).String(), fmt.Sprintf("context.Background.clock.WithDeadline(%s [1s])", want); s != want {
+		t.Fatalf("unexpected string: %s", s)
+	}
+
+	m.Add(time.Second)
+	for _, c := range []context.Context{parent, middle, valued, ctx, inner} {
+		<-c.Done()
+		if err := c.Err(); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		} else if err := context.Cause(c); !errors.Is(err, errBudget) {
+			t.Fatalf("unexpected cause: %v", err)
+		}
+	}
+}
+
+// Ensure that a mock child is done as soon as its mock parent is.
+func TestMock_WithDeadline_NestedCancel(t *testing.T) {
+	m := NewMock()
+	parent, cancelParent := m.WithTimeout(context.Background(), time.Hour)
+	child, cancelChild := m.WithTimeout(parent, time.Minute)
+	defer cancelChild()
+
+	cancelParent()
+	select {
+	case <-child.Done():
+	default:
+		t.Fatal("expected child to be done along with its parent")
+	}
+	if err := child.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := m.Pending(); len(p) != 0 {
+		t.Fatalf("expected timers to be stopped, got %v", p)
+	}
+}
+
+// Ensure that live mock contexts are listed with their remaining time.
+func TestMock_Contexts(t *testing.T) {
+	m := NewMock()
+	_, file, line, _ := runtime.Caller(0)
+	a, cancelA := m.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelA()
+	b, cancelB := m.WithDeadline(context.Background(), m.Now().Add(5*time.Second))
+	c, cancelC := m.WithTimeout(b, time.Minute)
+	defer cancelC()
+
+	m.Add(time.Second)
+	ctxs := m.Contexts()
+	if len(ctxs) != 3 {
+		t.Fatalf("expected 3 contexts, got %v", ctxs)
+	}
+	for i, want := range []struct {
+		ctx       context.Context
+		kind      TimerKind
+		remaining time.Duration
+		line      int
+	}{
+		{a, KindTimeout, time.Second, line + 1},
+		{b, KindDeadline, 4 * time.Second, line + 3},
+		{c, KindTimeout, 4 * time.Second, line + 4},
+	} {
+		p := ctxs[i]
+		if p.Context != want.ctx || p.Kind != want.kind || p.Remaining != want.remaining {
+			t.Fatalf("%d: unexpected context: %s", i, p)
+		} else if p.File != file || p.Line != want.line {
+			t.Fatalf("%d: unexpected creator: %s:%d", i, p.File, p.Line)
+		}
+	}
+	if s, want := ctxs[0].String(), fmt.Sprintf("timeout at 1970-01-01T00:00:02Z (1s left) created at context_test.go:%d", line+1); s != want {
+		t.Fatalf("unexpected string: %s", s)
+	}
+
+	// Timeouts keep their remaining duration across a step of the wall clock,
+	// while deadlines and their children wait for the wall clock.
+	m.Jump(-20 * time.Second)
+	for i, want := range []time.Duration{time.Second, 24 * time.Second, 24 * time.Second} {
+		if p := m.Contexts()[i]; p.Remaining != want {
+			t.Fatalf("%d: unexpected context after jump: %s", i, p)
+		}
+	}
+
+	cancelB()
+	m.Add(time.Second)
+	if ctxs := m.Contexts(); len(ctxs) != 0 {
+		t.Fatalf("expected no contexts, got %v", ctxs)
+	}
+}
+
+// Ensure that a clock can be carried by a context, and that mock contexts
+// carry their mock clock.
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()).(*clock); !ok {
+		t.Fatal("expected real-time clock by default")
+	}
+
+	m := NewMock()
+	ctx := WithClock(context.Background(), m)
+	if c := FromContext(ctx); c != m {
+		t.Fatalf("unexpected clock: %v", c)
+	}
+
+	other := NewMock()
+	ctx, cancel := other.WithTimeout(ctx, time.Second)
+	defer cancel()
+	child, cancelChild := context.WithCancel(ctx)
+	defer cancelChild()
+	if c := FromContext(child); c != other {
+		t.Fatal("expected clock of the mock context")
+	}
+
+	// Contexts of derived clocks carry the derived clock itself.
+	p := NewPausable(m)
+	ctx, cancel = p.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if c := FromContext(ctx); c != p {
+		t.Fatalf("expected pausable clock, got %T", c)
+	}
+}
@@ -0,0 +1,121 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SleepContext pauses the current goroutine for d on clock c, or until ctx is
+// done. Returns the error of ctx if it is done first, or nil otherwise. The
+// timer used to wait is stopped when ctx is done, so that it doesn't stay
+// registered on a mock clock.
+func SleepContext(ctx context.Context, c Clock, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	} else if d <= 0 {
+		return nil
+	}
+
+	t := c.Timer(d)
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		t.Stop()
+		return ctx.Err()
+	}
+}
+
+// AfterFunc waits for d to elapse on clock c and then calls f in its own
+// goroutine, unless ctx is done first. The returned Timer is stopped as soon
+// as ctx is done. f is never called once ctx.Err returns an error, even if
+// the timer fires before it is stopped.
+func AfterFunc(ctx context.Context, c Clock, d time.Duration, f func()) *Timer {
+	t := &contextTimer{ctx: ctx}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timer = c.AfterFunc(d, func() {
+		t.mu.Lock()
+		t.unwatch()
+		err := t.ctx.Err()
+		t.mu.Unlock()
+		if err == nil {
+			f()
+		}
+	})
+	t.watch()
+	return &Timer{impl: t}
+}
+
+// TimerContext creates a Timer on clock c that sends the current time on its
+// channel after d, unless ctx is done first. The timer is stopped as soon as
+// ctx is done.
+//
+// Stopping the timer happens asynchronously, in a function registered with
+// context.AfterFunc. If the timer fires after ctx is done but before it is
+// stopped, e.g. when a mock clock is moved forward right after cancelling
+// ctx, a receiver already waiting on C gets the value; an unreceived value
+// is discarded when the timer is stopped. Receivers that must not act after
+// cancellation should check ctx.Err after receiving.
+func TimerContext(ctx context.Context, c Clock, d time.Duration) *Timer {
+	t := &contextTimer{ctx: ctx}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timer = c.Timer(d)
+	t.watch()
+	return &Timer{C: t.timer.C, impl: t}
+}
+
+// contextTimer is a timer that is stopped when its context is done.
+type contextTimer struct {
+	ctx context.Context
+
+	mu    sync.Mutex
+	timer *Timer      // underlying timer
+	stop  func() bool // stops watching the context, if set
+}
+
+// watch arranges for the timer to be stopped when the context is done.
+// t.mu MUST be held when this method is called.
+func (t *contextTimer) watch() {
+	if t.ctx.Err() != nil {
+		t.timer.Stop()
+		return
+	}
+	t.stop = context.AfterFunc(t.ctx, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.timer.Stop()
+		t.stop = nil
+	})
+}
+
+// unwatch stops watching the context. t.mu MUST be held when this method is
+// called.
+func (t *contextTimer) unwatch() {
+	if t.stop != nil {
+		t.stop()
+		t.stop = nil
+	}
+}
+
+// Stop stops the timer and stops watching the context. Returns true if the
+// timer had not expired yet.
+func (t *contextTimer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unwatch()
+	return t.timer.Stop()
+}
+
+// Reset changes the timer to expire after d, unless the context is done.
+// Returns true if the timer had not expired yet.
+func (t *contextTimer) Reset(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unwatch()
+	active := t.timer.Reset(d)
+	t.watch()
+	return active
+}
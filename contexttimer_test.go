@@ -0,0 +1,115 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Ensure that SleepContext returns when either the duration elapses or the
+// context is done, removing its timer from the mock clock.
+func TestSleepContext(t *testing.T) {
+	m := NewMock()
+	errc := make(chan error, 1)
+	go func() { errc <- SleepContext(context.Background(), m, time.Second) }()
+	m.BlockUntil(1)
+	m.Add(time.Second)
+	if err := <-errc; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { errc <- SleepContext(ctx, m, time.Second) }()
+	m.BlockUntil(1)
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := m.Pending(); len(p) != 0 {
+		t.Fatalf("expected timer to be removed, got %v", p)
+	}
+
+	if err := SleepContext(ctx, New(), time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error for done context: %v", err)
+	}
+}
+
+// Ensure that a context AfterFunc runs unless the context is done first.
+func TestAfterFunc_Context(t *testing.T) {
+	var n int32
+	m := NewMock()
+	ctx, cancel := m.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	AfterFunc(ctx, m, time.Second, func() { atomic.AddInt32(&n, 1) })
+	AfterFunc(ctx, m, 3*time.Second, func() { atomic.AddInt32(&n, 10) })
+
+	m.Add(time.Second)
+	if got := atomic.LoadInt32(&n); got != 1 {
+		t.Fatalf("expected first function to run, got %d", got)
+	}
+	m.Add(time.Second)
+	m.WaitForCallbacks()
+	m.Add(time.Second)
+	if got := atomic.LoadInt32(&n); got != 1 {
+		t.Fatalf("expected second function not to run, got %d", got)
+	}
+}
+
+// Ensure that a context AfterFunc doesn't run once its context is cancelled,
+// even if the clock is moved before the timer is stopped.
+func TestAfterFunc_Context_Cancel(t *testing.T) {
+	var n int32
+	m := NewMock(WithAfterFuncMode(AfterFuncSync))
+	ctx, cancel := context.WithCancel(context.Background())
+	AfterFunc(ctx, m, time.Second, func() { atomic.AddInt32(&n, 1) })
+
+	cancel()
+	m.Add(time.Second)
+	if got := atomic.LoadInt32(&n); got != 0 {
+		t.Fatalf("expected function not to run, got %d", got)
+	}
+}
+
+// Ensure that a context timer is stopped when its context is done, and can
+// be reset while the context isn't.
+func TestTimerContext(t *testing.T) {
+	m := NewMock()
+	ctx, cancel := context.WithCancel(context.Background())
+	timer := TimerContext(ctx, m, time.Second)
+	m.Add(time.Second)
+	if v := <-timer.C; !v.Equal(m.Now()) {
+		t.Fatalf("unexpected timer value: %s", v)
+	}
+
+	if timer.Reset(time.Second) {
+		t.Fatal("expected Reset to report an expired timer")
+	}
+	cancel()
+	waitPending(t, m, 0)
+	m.Add(time.Second)
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired after its context was done")
+	default:
+	}
+
+	// Resetting the timer of a done context leaves it stopped.
+	timer.Reset(time.Second)
+	if p := m.Pending(); len(p) != 0 {
+		t.Fatalf("expected timer to stay stopped, got %v", p)
+	}
+}
+
+// waitPending waits for the number of timers registered on m to become n.
+func waitPending(t *testing.T, m *Mock, n int) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if len(m.Pending()) == n {
+			return
+		}
+		gosched()
+	}
+	t.Fatalf("expected %d pending timers, got %v", n, m.Pending())
+}
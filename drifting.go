@@ -0,0 +1,208 @@
+package clock
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Correction determines how a Drifting clock corrects its accumulated error.
+type Correction int
+
+const (
+	// CorrectionStep sets the clock to the time of its base clock at once,
+	// like NTP stepping the system clock. Stepping forward fires the timers
+	// that become due, running the functions of AfterFunc timers on the
+	// goroutine making the correction; stepping backward makes timers wait
+	// longer.
+	CorrectionStep Correction = iota
+
+	// CorrectionSlew removes the error gradually by running the clock faster
+	// or slower until the next correction, like NTP slewing the system
+	// clock. The error found at each correction is spread over the interval
+	// until the next one, adjusting the speed by at most maxSlew.
+	CorrectionSlew
+)
+
+// maxSlew is the largest adjustment in ppm made by CorrectionSlew, which is
+// the limit used by NTP implementations.
+const maxSlew = 500
+
+// DriftOption is an option for NewDrifting.
+type DriftOption func(*Drifting)
+
+// WithRandomWalk makes the drift of the clock change by a random amount of up
+// to step ppm every interval of base time. The changes are determined by seed.
+// If seed is zero, a seed is chosen from the current time.
+func WithRandomWalk(step float64, interval time.Duration, seed int64) DriftOption {
+	return func(d *Drifting) {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		d.rng = rand.New(rand.NewSource(seed))
+		d.walkStep, d.walkInterval = step, interval
+	}
+}
+
+// WithCorrection makes the clock correct its error relative to its base clock
+// every interval of base time, using the given kind of correction.
+func WithCorrection(interval time.Duration, c Correction) DriftOption {
+	return func(d *Drifting) {
+		d.correction, d.correctInterval = c, interval
+	}
+}
+
+// Drifting represents a clock that drifts away from its base clock, like an
+// imperfect oscillator. Its time passes faster or slower than the time of the
+// base clock by a rate given in parts per million, so a drift of 100 ppm
+// gains 8.64 seconds a day. Timers, tickers, sleepers and contexts wait for
+// drifted durations.
+//
+// The drift can change over time as a random walk, and the clock can be
+// periodically corrected back to the time of its base clock. See
+// WithRandomWalk and WithCorrection.
+type Drifting struct {
+	*timeline
+
+	// adj protects the fields below.
+	adj sync.Mutex
+
+	drift     float64  // drift in ppm
+	slew      float64  // current slew adjustment in ppm
+	stopped   bool     // true once Stop has been called
+	adjTimers []*Timer // base timers making periodic adjustments
+
+	rng          *rand.Rand
+	walkStep     float64
+	walkInterval time.Duration
+
+	correction      Correction
+	correctInterval time.Duration
+}
+
+// NewDrifting returns a clock that starts at the current time of base and
+// drifts from it by drift ppm.
+func NewDrifting(base Clock, drift float64, opts ...DriftOption) *Drifting {
+	d := &Drifting{drift: drift}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.timeline = newTimeline(base, base.Now(), d.speed())
+	d.owner = d
+
+	d.adj.Lock()
+	defer d.adj.Unlock()
+	if d.rng != nil && d.walkInterval > 0 {
+		d.every(d.walkInterval, d.walk)
+	}
+	if d.correctInterval > 0 {
+		d.every(d.correctInterval, d.correct)
+	}
+	return d
+}
+
+// Drift returns the current drift of the clock in ppm, excluding any slew
+// adjustment.
+func (d *Drifting) Drift() float64 {
+	d.adj.Lock()
+	defer d.adj.Unlock()
+	return d.drift
+}
+
+// SetDrift changes the drift of the clock to drift ppm.
+func (d *Drifting) SetDrift(drift float64) {
+	d.adj.Lock()
+	defer d.adj.Unlock()
+	d.drift = drift
+	d.setRate(d.speed())
+}
+
+// Offset returns how far the clock is ahead of its base clock.
+func (d *Drifting) Offset() time.Duration {
+	return d.Now().Sub(d.base.Now().Round(0))
+}
+
+// Correct corrects the error of the clock relative to its base clock now,
+// using the correction given to WithCorrection, or a step if there is none.
+func (d *Drifting) Correct() {
+	d.adj.Lock()
+	step := d.correct()
+	d.adj.Unlock()
+	d.step(step)
+}
+
+// Stop stops the random walk and periodic corrections of the clock. The clock
+// keeps running with its current drift.
+func (d *Drifting) Stop() {
+	d.adj.Lock()
+	defer d.adj.Unlock()
+	d.stopped = true
+	for _, t := range d.adjTimers {
+		t.Stop()
+	}
+	d.adjTimers = nil
+}
+
+// speed returns the rate of the clock relative to its base. d.adj MUST be
+// held when this method is called.
+func (d *Drifting) speed() float64 {
+	return 1 + (d.drift+d.slew)/1e6
+}
+
+// every calls fn every interval of base time until Stop is called, and then
+// steps the clock by the duration fn returns. d.adj MUST be held when this
+// method is called, and is held while fn is called.
+func (d *Drifting) every(interval time.Duration, fn func() time.Duration) {
+	i := len(d.adjTimers)
+	var tick func()
+	tick = func() {
+		d.adj.Lock()
+		if d.stopped {
+			d.adj.Unlock()
+			return
+		}
+		step := fn()
+		d.adjTimers[i] = d.base.AfterFunc(interval, tick)
+		d.adj.Unlock()
+		d.step(step)
+	}
+	d.adjTimers = append(d.adjTimers, d.base.AfterFunc(interval, tick))
+}
+
+// step steps the time of the clock by dt, if it isn't zero. d.adj MUST NOT be
+// held when this method is called, as AfterFunc functions that become due run
+// inline and may use the clock.
+func (d *Drifting) step(dt time.Duration) {
+	if dt != 0 {
+		d.advance(dt)
+	}
+}
+
+// walk changes the drift by a random step. It never steps the time of the
+// clock, so it always returns zero. d.adj MUST be held when this method is
+// called.
+func (d *Drifting) walk() time.Duration {
+	d.drift += (2*d.rng.Float64() - 1) * d.walkStep
+	d.setRate(d.speed())
+	return 0
+}
+
+// correct corrects the error of the clock. Returns the step to make to the
+// time of the clock once d.adj is released, or zero when slewing. d.adj MUST
+// be held when this method is called.
+func (d *Drifting) correct() time.Duration {
+	offset := d.Offset()
+	switch {
+	case d.correction == CorrectionSlew && d.correctInterval > 0:
+		d.slew = -float64(offset) / float64(d.correctInterval) * 1e6
+		if d.slew > maxSlew {
+			d.slew = maxSlew
+		} else if d.slew < -maxSlew {
+			d.slew = -maxSlew
+		}
+		d.setRate(d.speed())
+		return 0
+	default:
+		return -offset
+	}
+}
@@ -0,0 +1,134 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Ensure that a drifting clock's time and timers run at the drifted rate.
+func TestDrifting(t *testing.T) {
+	base := NewMock()
+	clock := NewDrifting(base, 100)
+	start := clock.Now()
+
+	// A timer waits for drifted time, so it fires early in base time.
+	timer := clock.Timer(time.Hour)
+	ctx, cancel := clock.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	base.Add(time.Hour - 359*time.Millisecond)
+	if v := <-timer.C; v.Sub(start) != time.Hour {
+		t.Fatalf("unexpected timer value: %s", v.Sub(start))
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+
+	base.Add(359 * time.Millisecond)
+	if d := clock.Offset(); d != 360*time.Millisecond {
+		t.Fatalf("unexpected offset: %s", d)
+	}
+
+	clock.SetDrift(-50)
+	if d := clock.Drift(); d != -50 {
+		t.Fatalf("unexpected drift: %v", d)
+	}
+	base.Add(time.Hour)
+	if d := clock.Offset(); d != 180*time.Millisecond {
+		t.Fatalf("unexpected offset: %s", d)
+	}
+}
+
+// Ensure that functions run by a step correction can use the clock.
+func TestDrifting_CorrectionStep_AfterFunc(t *testing.T) {
+	base := NewMock()
+	clock := NewDrifting(base, -1000, WithCorrection(time.Second, CorrectionStep))
+
+	// The clock is 1ms behind when corrected, so the step fires the timer.
+	done := make(chan float64, 1)
+	clock.AfterFunc(time.Second-500*time.Microsecond, func() {
+		clock.Correct()
+		clock.SetDrift(0)
+		done <- clock.Drift()
+	})
+	base.Add(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("AfterFunc function did not return")
+	}
+	if d := clock.Offset(); d != 0 {
+		t.Fatalf("unexpected offset after step: %s", d)
+	}
+	clock.Stop()
+}
+
+// Ensure that a drifting clock is stepped back to its base by corrections.
+func TestDrifting_CorrectionStep(t *testing.T) {
+	base := NewMock()
+	clock := NewDrifting(base, -200, WithCorrection(time.Hour, CorrectionStep))
+	defer clock.Stop()
+
+	// The clock falls 720ms behind per hour, so a timer it is waiting for
+	// fires when the step makes up for that.
+	timer := clock.Timer(time.Hour - 100*time.Millisecond)
+	base.Add(time.Hour)
+	select {
+	case <-timer.C:
+	default:
+		t.Fatal("expected the step to fire the timer")
+	}
+	if d := clock.Offset(); d != 0 {
+		t.Fatalf("unexpected offset after step: %s", d)
+	}
+}
+
+// Ensure that a drifting clock is slewed back towards its base by corrections.
+func TestDrifting_CorrectionSlew(t *testing.T) {
+	base := NewMock()
+	clock := NewDrifting(base, 100, WithCorrection(time.Hour, CorrectionSlew))
+	defer clock.Stop()
+
+	base.Add(time.Hour)
+	if d := clock.Offset(); d != 360*time.Millisecond {
+		t.Fatalf("unexpected offset before slew: %s", d)
+	}
+
+	// Without further drift, the slew of -100ppm removes the offset over
+	// the next hour.
+	clock.SetDrift(0)
+	base.Add(30 * time.Minute)
+	if d := clock.Offset(); d != 180*time.Millisecond {
+		t.Fatalf("unexpected offset while slewing: %s", d)
+	}
+	base.Add(90 * time.Minute)
+	if d := clock.Offset(); d != 0 {
+		t.Fatalf("unexpected offset after slew: %s", d)
+	}
+}
+
+// Ensure that the random walk of the drift is determined by the seed.
+func TestDrifting_RandomWalk(t *testing.T) {
+	drifts := func() []float64 {
+		base := NewMock()
+		clock := NewDrifting(base, 0, WithRandomWalk(10, time.Minute, 42))
+		defer clock.Stop()
+
+		var a []float64
+		for i := 0; i < 5; i++ {
+			base.Add(time.Minute)
+			a = append(a, clock.Drift())
+		}
+		return a
+	}
+
+	a, b := drifts(), drifts()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("drifts differ with the same seed: %v != %v", a, b)
+		}
+	}
+	if a[0] == 0 || a[0] < -10 || a[0] > 10 {
+		t.Fatalf("unexpected first step: %v", a[0])
+	}
+}
@@ -0,0 +1,47 @@
+package clock
+
+import "time"
+
+// Hybrid represents a clock that follows the time of its base clock until it
+// is frozen. While frozen, its time only moves when it is advanced manually,
+// like a mock clock. Once resumed, it follows the base clock again from the
+// time it was advanced to.
+//
+// Pending timers, tickers, sleepers and contexts carry over between the two
+// modes. Each fires exactly once when its time is reached, whether that is
+// by following the base clock or by Advance.
+type Hybrid struct {
+	*timeline
+}
+
+// NewHybrid returns a clock that follows base, starting at its current time.
+func NewHybrid(base Clock) *Hybrid {
+	h := &Hybrid{timeline: newTimeline(base, base.Now(), 1)}
+	h.owner = h
+	return h
+}
+
+// Freeze stops the clock from following its base clock. It has no effect if
+// the clock is already frozen.
+func (h *Hybrid) Freeze() { h.setRate(0) }
+
+// Advance moves the time of the clock forward by d, firing the timers due
+// until then in chronological order. Functions of AfterFunc timers run on the
+// calling goroutine before Advance returns, like those of a mock clock in
+// AfterFuncSync mode. Unlike Mock.Add, Advance doesn't wait for goroutines
+// receiving from timers and tickers to react. The clock may be advanced
+// whether or not it is frozen. Advance panics if d is negative, as the clock
+// can't move backward.
+func (h *Hybrid) Advance(d time.Duration) {
+	if d < 0 {
+		panic("negative duration for Hybrid.Advance")
+	}
+	h.advance(d)
+}
+
+// Resume makes the clock follow its base clock again from its current time.
+// It has no effect if the clock isn't frozen.
+func (h *Hybrid) Resume() { h.setRate(1) }
+
+// Frozen returns true if the clock is frozen.
+func (h *Hybrid) Frozen() bool { return h.getRate() == 0 }
@@ -0,0 +1,81 @@
+package clock
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Ensure that timers carry over between following the base clock and being
+// advanced manually, firing exactly once.
+func TestHybrid(t *testing.T) {
+	base := NewMock()
+	clock := NewHybrid(base)
+	start := clock.Now()
+
+	var n int32
+	timer := clock.Timer(10 * time.Second)
+	ticker := clock.Ticker(4 * time.Second)
+	defer ticker.Stop()
+	clock.AfterFunc(20*time.Second, func() { atomic.AddInt32(&n, 1) })
+
+	base.Add(5 * time.Second)
+	if v := <-ticker.C; v.Sub(start) != 4*time.Second {
+		t.Fatalf("unexpected tick: %s", v.Sub(start))
+	}
+
+	clock.Freeze()
+	if !clock.Frozen() {
+		t.Fatal("expected clock to be frozen")
+	}
+	base.Add(time.Hour)
+	if d := clock.Since(start); d != 5*time.Second {
+		t.Fatalf("unexpected elapsed time while frozen: %s", d)
+	}
+
+	clock.Advance(5 * time.Second)
+	if v := <-timer.C; v.Sub(start) != 10*time.Second {
+		t.Fatalf("unexpected timer value: %s", v.Sub(start))
+	}
+	if v := <-ticker.C; v.Sub(start) != 8*time.Second {
+		t.Fatalf("unexpected tick: %s", v.Sub(start))
+	}
+
+	// The timers continue from the advanced time once resumed.
+	clock.Resume()
+	base.Add(2 * time.Second)
+	if v := <-ticker.C; v.Sub(start) != 12*time.Second {
+		t.Fatalf("unexpected tick: %s", v.Sub(start))
+	}
+	base.Add(8 * time.Second)
+	if got := atomic.LoadInt32(&n); got != 1 {
+		t.Fatalf("expected AfterFunc to run once, got %d", got)
+	}
+	if timer.Stop() {
+		t.Fatal("expected timer to have fired")
+	}
+	select {
+	case v := <-timer.C:
+		t.Fatalf("timer fired twice: %s", v.Sub(start))
+	default:
+	}
+}
+
+// Ensure that advancing by a negative duration panics without moving the
+// clock.
+func TestHybrid_Advance_Negative(t *testing.T) {
+	clock := NewHybrid(NewMock())
+	clock.Freeze()
+	start := clock.Now()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		clock.Advance(-time.Second)
+	}()
+	if now := clock.Now(); !now.Equal(start) {
+		t.Fatalf("clock moved: %s", now)
+	}
+}
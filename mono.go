@@ -0,0 +1,82 @@
+package clock
+
+import (
+	"time"
+	"unsafe"
+)
+
+// The standard library provides no way to create a time.Time with a monotonic
+// clock reading other than time.Now, and Add shifts the wall clock and
+// monotonic readings together. To let mock times carry a monotonic reading
+// that is independent from their wall time, they are built directly using the
+// time.Time representation, which has been stable since Go 1.9:
+//
+//	wall: hasMonotonic flag (1 bit), seconds since 1885 (33 bits), nanoseconds (30 bits)
+//	ext:  monotonic clock reading in nanoseconds
+//	loc:  location, nil for UTC
+//
+// The representation is verified on startup; if it ever changes, WithMonotonic
+// makes NewMock panic.
+
+// timeLayout mirrors the representation of time.Time.
+type timeLayout struct {
+	wall uint64
+	ext  int64
+	loc  *time.Location
+}
+
+const (
+	hasMonotonic = 1 << 63
+	nsecShift    = 30
+)
+
+// year1885 is the Unix time of the epoch of wall seconds in a time.Time with
+// a monotonic reading.
+var year1885 = time.Date(1885, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+// monotonicSupported is true if times with a monotonic reading can be built.
+var monotonicSupported = func() bool {
+	if unsafe.Sizeof(time.Time{}) != unsafe.Sizeof(timeLayout{}) {
+		return false
+	}
+
+	wall := time.Unix(1e9, 5).In(time.FixedZone("", 3600))
+	a := withMonotonic(wall, 100)
+	b := withMonotonic(wall.Add(time.Hour), 100+int64(time.Second))
+	return a.Round(0) == wall &&
+		b.Sub(a) == time.Second &&
+		b.Round(0).Sub(a.Round(0)) == time.Hour &&
+		a.Location() == wall.Location()
+}()
+
+// withMonotonic returns t with its monotonic clock reading set to mono. Returns
+// t without a monotonic reading if it is outside the range that supports one.
+func withMonotonic(t time.Time, mono int64) time.Time {
+	t = t.Round(0)
+	sec := t.Unix() - year1885
+	if sec < 0 || sec >= 1<<33 {
+		return t
+	}
+
+	var loc *time.Location
+	if l := t.Location(); l != time.UTC {
+		loc = l
+	}
+	r := timeLayout{
+		wall: hasMonotonic | uint64(sec)<<nsecShift | uint64(t.Nanosecond()),
+		ext:  mono,
+		loc:  loc,
+	}
+	return *(*time.Time)(unsafe.Pointer(&r))
+}
+
+// stamp returns the wall time t with the monotonic reading of the mock clock
+// at that time, if the mock clock was created with WithMonotonic. m.mu MUST be
+// held when this method is called.
+func (m *Mock) stamp(t time.Time) time.Time {
+	if !m.monotonic {
+		return t
+	}
+	// Readings start at one, like those of the runtime.
+	return withMonotonic(t, int64(t.Sub(m.start)-m.jumped)+1)
+}
@@ -0,0 +1,85 @@
+package clock
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Ensure that mock times carry a monotonic reading unaffected by wall clock steps.
+func TestMock_Now_Monotonic(t *testing.T) {
+	if !monotonicSupported {
+		t.Fatal("monotonic readings unsupported by this version of Go")
+	}
+
+	clock := NewMock(WithStart(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)), WithMonotonic())
+	start := clock.Now()
+	if s := start.String(); !strings.Contains(s, " m=+") {
+		t.Fatalf("expected monotonic reading: %s", s)
+	}
+
+	timer := clock.Timer(10 * time.Second)
+	clock.SetWall(start.Add(-time.Hour))
+	clock.Add(5 * time.Second)
+
+	if d := clock.Since(start); d != 5*time.Second {
+		t.Fatalf("unexpected monotonic duration: %s", d)
+	}
+	if d := clock.Now().Round(0).Sub(start.Round(0)); d != -time.Hour+5*time.Second {
+		t.Fatalf("unexpected wall duration: %s", d)
+	}
+	if now := clock.Now(); !now.After(start) {
+		t.Fatalf("expected comparison to use monotonic reading: %s", now)
+	}
+
+	clock.Add(5 * time.Second)
+	if v := <-timer.C; v.Sub(start) != 10*time.Second {
+		t.Fatalf("unexpected timer value: %s", v)
+	}
+}
+
+// Ensure that mock times carry no monotonic reading by default.
+func TestMock_Now_Plain(t *testing.T) {
+	clock := NewMock(WithLocation(time.UTC))
+	if now := clock.Now(); !reflect.DeepEqual(now, time.Unix(0, 0).UTC()) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+
+	timer := clock.Timer(time.Second)
+	clock.SetWall(time.Unix(100, 0))
+	clock.Add(time.Second)
+	if v := <-timer.C; v != time.Unix(101, 0).UTC() {
+		t.Fatalf("unexpected timer value: %s", v)
+	}
+}
+
+// Ensure that times built with a monotonic reading keep their wall time and location.
+func TestWithMonotonic(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	for _, wall := range []time.Time{
+		time.Unix(0, 0).UTC(),
+		time.Date(2024, 2, 29, 23, 59, 59, 999999999, loc),
+		time.Date(1885, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		got := withMonotonic(wall, 42)
+		if !got.Round(0).Equal(wall) || got.Location() != wall.Location() {
+			t.Errorf("withMonotonic(%s)=%s", wall, got)
+		}
+	}
+}
+
+// Ensure that WithMonotonic panics if times with a monotonic reading can't be
+// built.
+func TestWithMonotonic_Unsupported(t *testing.T) {
+	defer func(v bool) { monotonicSupported = v }(monotonicSupported)
+	monotonicSupported = false
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	NewMock(WithMonotonic())
+}
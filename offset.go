@@ -0,0 +1,84 @@
+package clock
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Offset represents a clock whose current time is shifted from the time of
+// its base clock by an adjustable offset. It can be used to run a process as
+// if it were some other time, such as the end of a month, without changing
+// the system clock.
+//
+// Only the current time is shifted. Timers, tickers, sleepers and contexts
+// wait for the same durations as they do on the base clock, and are not
+// affected by changes to the offset. The deadlines of contexts move along with
+// the offset, so that they stay the same duration away.
+type Offset struct {
+	*timeline
+}
+
+// NewOffset returns a clock whose time is the time of base shifted by offset.
+func NewOffset(base Clock, offset time.Duration) *Offset {
+	// Shift the anchor itself rather than reading the base clock again, so
+	// that the offset is exact.
+	tl := newTimeline(base, time.Time{}, 1)
+	tl.anchorV = tl.anchorB.Round(0).Add(offset)
+	o := &Offset{timeline: tl}
+	o.owner = o
+	return o
+}
+
+// NewOffsetAt returns a clock whose time starts at start and then passes like
+// the time of base.
+func NewOffsetAt(base Clock, start time.Time) *Offset {
+	o := &Offset{timeline: newTimeline(base, start, 1)}
+	o.owner = o
+	return o
+}
+
+// OffsetFromEnv returns a clock over base configured by the environment
+// variable key. The variable may hold either an offset, parsed by
+// time.ParseDuration, or a start time in RFC 3339 format. If the variable is
+// unset or empty, the clock is not shifted.
+func OffsetFromEnv(base Clock, key string) (*Offset, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return NewOffset(base, 0), nil
+	} else if d, err := time.ParseDuration(v); err == nil {
+		return NewOffset(base, d), nil
+	} else if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return NewOffsetAt(base, t), nil
+	}
+	return nil, fmt.Errorf("clock: invalid %s: %q is neither a duration nor an RFC 3339 time", key, v)
+}
+
+// Offset returns the duration the clock is shifted from its base by.
+func (o *Offset) Offset() time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.offset()
+}
+
+// SetOffset changes the duration the clock is shifted from its base by.
+func (o *Offset) SetOffset(d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.shift(d - o.offset())
+}
+
+// offset returns the duration the clock is shifted from its base by. As the
+// clock runs at the speed of its base, this is the same at any time, so it is
+// computed from the anchor rather than from separate readings of both clocks.
+// o.mu MUST be held when this method is called.
+func (o *Offset) offset() time.Duration {
+	return o.anchorV.Sub(o.anchorB.Round(0))
+}
+
+// Set shifts the clock so that its current time is t.
+func (o *Offset) Set(t time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.shift(t.Round(0).Sub(o.now()))
+}
@@ -0,0 +1,113 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Ensure that an offset clock shifts the current time but not durations.
+func TestOffset(t *testing.T) {
+	base := NewMock()
+	clock := NewOffset(base, 24*time.Hour)
+	if d := clock.Now().Sub(base.Now()); d != 24*time.Hour {
+		t.Fatalf("unexpected offset: %s", d)
+	}
+
+	timer := clock.Timer(time.Second)
+	ctx, cancel := clock.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if d, _ := ctx.Deadline(); !d.Equal(clock.Now().Add(time.Second)) {
+		t.Fatalf("unexpected deadline: %s", d)
+	}
+
+	// Changing the offset doesn't affect pending timers.
+	clock.SetOffset(-time.Hour)
+	if d := clock.Offset(); d != -time.Hour {
+		t.Fatalf("unexpected offset: %s", d)
+	}
+	base.Add(time.Second)
+	if v := <-timer.C; !v.Equal(clock.Now()) {
+		t.Fatalf("unexpected timer value: %s", v)
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+}
+
+// Ensure that changing the offset moves the deadlines of contexts with it.
+func TestOffset_SetOffset_Deadline(t *testing.T) {
+	base := NewMock()
+	clock := NewOffset(base, 0)
+	ctx, cancel := clock.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	clock.SetOffset(time.Hour)
+	if d, _ := ctx.Deadline(); d.Sub(clock.Now()) != time.Minute {
+		t.Fatalf("unexpected deadline: %s, now %s", d, clock.Now())
+	}
+	base.Add(time.Minute)
+	base.WaitForCallbacks()
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	if n := len(clock.contexts); n != 0 {
+		t.Fatalf("expected done context to be unregistered, got %d", n)
+	}
+}
+
+// Ensure that the offset over the real-time clock is exact.
+func TestOffset_Realtime(t *testing.T) {
+	clock := NewOffset(New(), time.Hour)
+	if d := clock.Offset(); d != time.Hour {
+		t.Fatalf("unexpected offset: %s", d)
+	}
+	clock.SetOffset(-time.Minute)
+	if d := clock.Offset(); d != -time.Minute {
+		t.Fatalf("unexpected offset: %s", d)
+	}
+}
+
+// Ensure that an offset clock can be anchored to a start time.
+func TestOffset_Set(t *testing.T) {
+	base := NewMock()
+	start := time.Date(2024, 2, 29, 23, 59, 0, 0, time.UTC)
+	clock := NewOffsetAt(base, start)
+	base.Add(time.Minute)
+	if now := clock.Now(); !now.Equal(start.Add(time.Minute)) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+
+	clock.Set(start)
+	if now := clock.Now(); !now.Equal(start) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+}
+
+// Ensure that an offset clock can be configured by an environment variable.
+func TestOffsetFromEnv(t *testing.T) {
+	base := NewMock()
+	for _, tt := range []struct {
+		value string
+		want  time.Time
+	}{
+		{"", base.Now()},
+		{"-90m", base.Now().Add(-90 * time.Minute)},
+		{"2024-12-31T23:59:59Z", time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)},
+	} {
+		t.Setenv("CLOCK_OFFSET", tt.value)
+		clock, err := OffsetFromEnv(base, "CLOCK_OFFSET")
+		if err != nil {
+			t.Fatalf("%q: %v", tt.value, err)
+		} else if now := clock.Now(); !now.Equal(tt.want) {
+			t.Errorf("%q: Now()=%s, want %s", tt.value, now, tt.want)
+		}
+	}
+
+	t.Setenv("CLOCK_OFFSET", "tomorrow")
+	if _, err := OffsetFromEnv(base, "CLOCK_OFFSET"); err == nil {
+		t.Fatal("expected error")
+	}
+}
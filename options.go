@@ -0,0 +1,87 @@
+package clock
+
+import (
+	"math/rand"
+	"time"
+)
+
+// MockOption configures a Mock created by NewMock.
+type MockOption func(m *Mock)
+
+// WithStart sets the initial current time of the mock clock. The default is
+// the Unix epoch.
+func WithStart(t time.Time) MockOption {
+	return func(m *Mock) { m.now = t }
+}
+
+// WithLocation sets the location of the times returned by the mock clock's
+// Now and sent by its timers. By default the location of the start time is
+// used, which is time.Local.
+func WithLocation(loc *time.Location) MockOption {
+	return func(m *Mock) { m.loc = loc }
+}
+
+// WithMonotonic makes the times returned by the mock clock's Now and sent by
+// its timers carry a monotonic clock reading, like those of time.Now. The
+// reading only advances as time passes on the mock clock, so durations
+// between such times are unaffected by Jump and SetWall.
+//
+// Such times no longer compare equal to plain times with == or
+// reflect.DeepEqual, and the readings of different mock clocks are
+// unrelated, so times of different clocks must not be subtracted from each
+// other. Use Round(0) to strip the reading.
+//
+// The readings are set by relying on the internal representation of
+// time.Time. NewMock panics if that representation has changed in the Go
+// version in use, rather than return times without a reading.
+func WithMonotonic() MockOption {
+	return func(m *Mock) {
+		if !monotonicSupported {
+			panic("clock: monotonic readings are not supported by this Go version")
+		}
+		m.monotonic = true
+	}
+}
+
+// WithSettleStrategy sets how the mock clock waits for goroutines woken by a
+// timer to react. See Mock.SetSettleStrategy.
+func WithSettleStrategy(s SettleStrategy) MockOption {
+	return func(m *Mock) { m.settle = s }
+}
+
+// WithLegacyTimers makes mock timers and tickers use the semantics from before
+// Go 1.23. See Mock.SetLegacyTimers.
+func WithLegacyTimers() MockOption {
+	return func(m *Mock) { m.legacy = true }
+}
+
+// WithTickerPolicy sets the policy of the mock clock's tickers. See
+// TickerPolicy.
+func WithTickerPolicy(p TickerPolicy) MockOption {
+	return func(m *Mock) { m.tickerPolicy = p }
+}
+
+// WithAfterFuncMode sets how the mock clock runs AfterFunc functions. See
+// AfterFuncMode.
+func WithAfterFuncMode(mode AfterFuncMode) MockOption {
+	return func(m *Mock) { m.afterFuncMode = mode }
+}
+
+// WithRandomOrder makes the mock clock fire timers due within epsilon of each
+// other in a random order, and yield to the goroutines it wakes a random
+// number of times. This can be used to find code that accidentally depends
+// on the order in which timers fire.
+//
+// The order is fully determined by seed, so a failing interleaving can be
+// replayed by passing the same seed. If seed is zero, a seed is chosen from
+// the current time. Use Mock.Seed or Mock.LogSeedOnFailure to report it.
+func WithRandomOrder(seed int64, epsilon time.Duration) MockOption {
+	return func(m *Mock) {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		m.rng = rand.New(rand.NewSource(seed))
+		m.seed = seed
+		m.epsilon = epsilon
+	}
+}
@@ -0,0 +1,42 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// Ensure that the mock clock starts at the configured time and location.
+func TestNewMock_WithStart(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	start := time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC)
+	clock := NewMock(WithStart(start), WithLocation(loc))
+
+	if now := clock.Now(); !now.Equal(start) || now.Location() != loc {
+		t.Fatalf("unexpected start: %s", now)
+	}
+
+	timer := clock.Timer(time.Hour)
+	clock.Add(time.Hour)
+	if v := <-timer.C; !v.Equal(start.Add(time.Hour)) || v.Location() != loc {
+		t.Fatalf("unexpected timer value: %s", v)
+	}
+
+	clock.Set(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	if now := clock.Now(); now.Location() != loc || now.Hour() != 14 {
+		t.Fatalf("unexpected time after Set: %s", now)
+	}
+}
+
+// Ensure that behavior modes can be set with options.
+func TestNewMock_Modes(t *testing.T) {
+	clock := NewMock(WithSettleStrategy(SettleNone), WithLegacyTimers())
+	if clock.settle != SettleNone {
+		t.Fatalf("unexpected settle strategy: %v", clock.settle)
+	} else if !clock.legacy {
+		t.Fatal("expected legacy timers")
+	}
+
+	if now := NewMock().Now(); !now.Equal(time.Unix(0, 0)) {
+		t.Fatalf("expected epoch, got: %s", now)
+	}
+}
@@ -0,0 +1,27 @@
+package clock
+
+// Pausable represents a clock that follows the time of its base clock but can
+// be paused, e.g. during a maintenance window. While it is paused its time
+// stands still and its timers, tickers, sleepers and contexts are suspended.
+// Once resumed, they wait for the time they had remaining.
+type Pausable struct {
+	*timeline
+}
+
+// NewPausable returns a running clock that starts at the current time of base.
+func NewPausable(base Clock) *Pausable {
+	p := &Pausable{timeline: newTimeline(base, base.Now(), 1)}
+	p.owner = p
+	return p
+}
+
+// Pause stops the time of the clock. It has no effect if the clock is already
+// paused.
+func (p *Pausable) Pause() { p.setRate(0) }
+
+// Resume continues the time of the clock from where it was paused. It has no
+// effect if the clock is running.
+func (p *Pausable) Resume() { p.setRate(1) }
+
+// Paused returns true if the clock is paused.
+func (p *Pausable) Paused() bool { return p.getRate() == 0 }
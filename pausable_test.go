@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Ensure that pausing a clock stops its time and suspends its timers.
+func TestPausable(t *testing.T) {
+	base := NewMock()
+	clock := NewPausable(base)
+	start := clock.Now()
+
+	var n int32
+	timer := clock.Timer(10 * time.Second)
+	clock.AfterFunc(10*time.Second, func() { atomic.AddInt32(&n, 1) })
+	ctx, cancel := clock.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	base.Add(4 * time.Second)
+	clock.Pause()
+	if !clock.Paused() {
+		t.Fatal("expected clock to be paused")
+	}
+	base.Add(time.Hour)
+	if d := clock.Since(start); d != 4*time.Second {
+		t.Fatalf("unexpected elapsed time while paused: %s", d)
+	}
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired while paused")
+	case <-ctx.Done():
+		t.Fatal("context done while paused")
+	default:
+	}
+
+	// The timers wait for the remaining 6 seconds after resuming.
+	clock.Resume()
+	base.Add(5 * time.Second)
+	if got := atomic.LoadInt32(&n); got != 0 {
+		t.Fatal("AfterFunc ran early")
+	}
+	base.Add(time.Second)
+	if v := <-timer.C; v.Sub(start) != 10*time.Second {
+		t.Fatalf("unexpected timer value: %s", v.Sub(start))
+	}
+	if got := atomic.LoadInt32(&n); got != 1 {
+		t.Fatalf("expected AfterFunc to run once, got %d", got)
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+}
+
+// Ensure that a paused ticker continues with the same phase when resumed.
+func TestPausable_Ticker(t *testing.T) {
+	base := NewMock()
+	clock := NewPausable(base)
+	start := clock.Now()
+	ticker := clock.Ticker(time.Second)
+	defer ticker.Stop()
+
+	base.Add(1500 * time.Millisecond)
+	<-ticker.C
+	clock.Pause()
+	base.Add(time.Minute)
+	clock.Resume()
+	base.Add(500 * time.Millisecond)
+	if v := <-ticker.C; v.Sub(start) != 2*time.Second {
+		t.Fatalf("unexpected tick: %s", v.Sub(start))
+	}
+}
@@ -0,0 +1,210 @@
+package clock
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimerKind identifies what a pending mock timer was created for.
+type TimerKind int
+
+const (
+	KindTimer     TimerKind = iota // Timer or After
+	KindTicker                     // Ticker or Tick
+	KindAfterFunc                  // AfterFunc
+	KindSleep                      // Sleep
+	KindDeadline                   // WithDeadline context
+	KindTimeout                    // WithTimeout context
+)
+
+// String returns a short name for the kind.
+func (k TimerKind) String() string {
+	switch k {
+	case KindTimer:
+		return "timer"
+	case KindTicker:
+		return "ticker"
+	case KindAfterFunc:
+		return "AfterFunc"
+	case KindSleep:
+		return "sleep"
+	case KindDeadline:
+		return "deadline"
+	case KindTimeout:
+		return "timeout"
+	default:
+		return fmt.Sprintf("TimerKind(%d)", int(k))
+	}
+}
+
+// PendingTimer describes a timer registered on a mock clock.
+type PendingTimer struct {
+	Kind   TimerKind
+	Next   time.Time     // next time the timer fires
+	Period time.Duration // time between ticks, zero unless Kind is KindTicker
+
+	// File and Line identify the code that created the timer. They are empty
+	// if the creator could not be determined.
+	File string
+	Line int
+}
+
+// String returns a human readable description of the timer, such as
+// "ticker every 5s created at worker.go:88".
+func (p PendingTimer) String() string {
+	var s string
+	if p.Kind == KindTicker {
+		s = fmt.Sprintf("%s every %s", p.Kind, p.Period)
+	} else {
+		s = fmt.Sprintf("%s at %s", p.Kind, p.Next.Format(time.RFC3339Nano))
+	}
+	if p.File != "" {
+		s += fmt.Sprintf(" created at %s:%d", filepath.Base(p.File), p.Line)
+	}
+	return s
+}
+
+// newPendingTimer returns a PendingTimer with the creator resolved from pcs.
+func newPendingTimer(kind TimerKind, next time.Time, period time.Duration, pcs []uintptr) PendingTimer {
+	p := PendingTimer{Kind: kind, Next: next, Period: period}
+	if len(pcs) == 0 {
+		return p
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !isInternalFile(frame.File) {
+			p.File, p.Line = frame.File, frame.Line
+			break
+		} else if !more {
+			break
+		}
+	}
+	return p
+}
+
+// Pending returns a snapshot of every timer, ticker, sleeper and context
+// deadline registered on the mock clock, ordered by the time they next fire.
+func (m *Mock) Pending() []PendingTimer {
+	m.mu.Lock()
+	a := make([]PendingTimer, len(m.timers))
+	for i, t := range m.timers {
+		a[i] = t.pending()
+	}
+	m.mu.Unlock()
+
+	sort.SliceStable(a, func(i, j int) bool { return a[i].Next.Before(a[j].Next) })
+	return a
+}
+
+// NextDeadline returns the time at which the next registered timer fires.
+// Returns false if no timers are registered.
+func (m *Mock) NextDeadline() (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.timers) == 0 {
+		return time.Time{}, false
+	}
+	return m.timers[0].Next(), true
+}
+
+// PendingContext describes a context with a deadline created by a mock clock
+// that isn't done yet.
+type PendingContext struct {
+	Context   context.Context
+	Kind      TimerKind     // KindDeadline or KindTimeout
+	Deadline  time.Time     // deadline of the context, or of its parent if sooner
+	Remaining time.Duration // time until the context expires on the mock clock
+
+	// File and Line identify the code that created the context. They are
+	// empty if the creator could not be determined.
+	File string
+	Line int
+}
+
+// String returns a human readable description of the context, such as
+// "timeout at 1970-01-01T00:00:05Z (5s left) created at server.go:42".
+func (p PendingContext) String() string {
+	s := fmt.Sprintf("%s at %s (%s left)", p.Kind, p.Deadline.Format(time.RFC3339Nano), p.Remaining)
+	if p.File != "" {
+		s += fmt.Sprintf(" created at %s:%d", filepath.Base(p.File), p.Line)
+	}
+	return s
+}
+
+// Contexts returns a snapshot of the contexts created by WithDeadline and
+// WithTimeout on the mock clock that aren't done yet, ordered by deadline.
+func (m *Mock) Contexts() []PendingContext {
+	m.mu.Lock()
+	ctxs := make([]*timerCtx, 0, len(m.contexts))
+	for ctx := range m.contexts {
+		ctxs = append(ctxs, ctx)
+	}
+	sort.Slice(ctxs, func(i, j int) bool { return ctxs[i].seq < ctxs[j].seq })
+
+	a := make([]PendingContext, 0, len(ctxs))
+	for _, ctx := range ctxs {
+		t := newPendingTimer(ctx.kind, ctx.deadline, 0, ctx.caller)
+		a = append(a, PendingContext{
+			Context:   ctx,
+			Kind:      ctx.kind,
+			Deadline:  ctx.deadline,
+			Remaining: m.contextExpiry(ctx).Sub(m.now),
+			File:      t.File,
+			Line:      t.Line,
+		})
+	}
+	m.mu.Unlock()
+
+	sort.SliceStable(a, func(i, j int) bool { return a[i].Deadline.Before(a[j].Deadline) })
+	return a
+}
+
+// contextExpiry returns the time at which ctx expires on the mock clock. This
+// is the time its timer, or that of the parent it took its deadline from, is
+// due at, which differs from the deadline of a WithTimeout context once the
+// wall clock has been stepped by Jump. m.mu MUST be held when this method is
+// called.
+func (m *Mock) contextExpiry(ctx *timerCtx) time.Time {
+	for c := ctx; ; {
+		c.Lock()
+		timer, parent := c.timer, c.parent
+		c.Unlock()
+		if timer != nil && timer.mock == m {
+			return timer.next
+		}
+
+		// A context without a timer of its own expires along with the
+		// parent it took its deadline from.
+		p, ok := parent.(*timerCtx)
+		if timer != nil || !ok {
+			return ctx.deadline
+		}
+		c = p
+	}
+}
+
+// pkgDir is the directory containing the source of this package.
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// isInternalFile returns true if file is a non-test source file of this package.
+func isInternalFile(file string) bool {
+	return filepath.Dir(file) == pkgDir && !strings.HasSuffix(file, "_test.go")
+}
+
+// callers returns the call stack of the caller, used to find the creator of
+// a mock timer.
+func callers() []uintptr {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	return append([]uintptr(nil), pcs[:n]...)
+}
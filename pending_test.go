@@ -0,0 +1,53 @@
+package clock
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Ensure that pending timers are reported in firing order with their creator.
+func TestMock_Pending(t *testing.T) {
+	clock := NewMock()
+	if p := clock.Pending(); len(p) != 0 {
+		t.Fatalf("expected no pending timers, got %v", p)
+	}
+	if _, ok := clock.NextDeadline(); ok {
+		t.Fatal("expected no next deadline")
+	}
+
+	_, file, line, _ := runtime.Caller(0)
+	clock.Ticker(5 * time.Second)
+	clock.AfterFunc(3*time.Second, func() {})
+	clock.Timer(4 * time.Second)
+	_, cancel := clock.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go clock.Sleep(time.Second)
+	clock.BlockUntil(5)
+
+	pending := clock.Pending()
+	for i, kind := range []TimerKind{KindSleep, KindTimeout, KindAfterFunc, KindTimer, KindTicker} {
+		if pending[i].Kind != kind {
+			t.Fatalf("%d: expected %s, got %s", i, kind, pending[i].Kind)
+		} else if want := time.Unix(int64(i+1), 0); !pending[i].Next.Equal(want) {
+			t.Fatalf("%d: expected next %s, got %s", i, want, pending[i].Next)
+		}
+	}
+	for i, want := range []int{line + 1, line + 3, line + 2, line + 4} {
+		p := pending[len(pending)-1-i]
+		if p.File != file || p.Line != want {
+			t.Fatalf("%s: expected creator %s:%d, got %s:%d", p.Kind, file, want, p.File, p.Line)
+		}
+	}
+
+	want := fmt.Sprintf("ticker every 5s created at %s:%d", filepath.Base(file), line+1)
+	if s := pending[4].String(); s != want {
+		t.Fatalf("unexpected string: %q", s)
+	}
+	if next, ok := clock.NextDeadline(); !ok || !next.Equal(time.Unix(1, 0)) {
+		t.Fatalf("unexpected next deadline: %s", next)
+	}
+}
@@ -0,0 +1,59 @@
+package clock
+
+import "time"
+
+// Seed returns the seed used to randomize the order of timers. Returns zero
+// if the mock clock was not created with WithRandomOrder.
+func (m *Mock) Seed() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.seed
+}
+
+// LogSeedOnFailure logs the seed used to randomize the order of timers at the
+// end of the test if it failed. The argument is typically a *testing.T.
+func (m *Mock) LogSeedOnFailure(tb interface {
+	Cleanup(func())
+	Failed() bool
+	Logf(format string, args ...interface{})
+}) {
+	tb.Cleanup(func() {
+		if seed := m.Seed(); tb.Failed() && seed != 0 {
+			tb.Logf("clock: mock timer order randomized with seed %d", seed)
+		}
+	})
+}
+
+// randomTimer returns a random timer among those due within m.epsilon of the
+// earliest timer and no later than max. m.mu MUST be held when this method is
+// called and m.timers MUST NOT be empty.
+func (m *Mock) randomTimer(max time.Time) clockTimer {
+	bound := m.timers[0].Next().Add(m.epsilon)
+	if bound.After(max) {
+		bound = max
+	}
+
+	// Walk the heap from the root, skipping subtrees that are due after the
+	// bound. The walk is deterministic, so the choice only depends on rng.
+	var candidates []clockTimer
+	stack := []int{0}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if i >= len(m.timers) || m.timers[i].Next().After(bound) {
+			continue
+		}
+		candidates = append(candidates, m.timers[i])
+		stack = append(stack, 2*i+2, 2*i+1)
+	}
+	return candidates[m.rng.Intn(len(candidates))]
+}
+
+// jitter returns a random number of times to yield to woken goroutines.
+// m.mu MUST be held when this method is called.
+func (m *Mock) jitter() int {
+	if m.rng == nil {
+		return 0
+	}
+	return m.rng.Intn(4)
+}
@@ -0,0 +1,102 @@
+package clock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fireOrder returns the order in which timers with nearby deadlines fire on a
+// mock clock randomized with seed.
+func fireOrder(seed int64, epsilon time.Duration) string {
+	clock := NewMock(
+		WithRandomOrder(seed, epsilon),
+		WithAfterFuncMode(AfterFuncSync),
+		WithSettleStrategy(SettleNone),
+	)
+
+	var order []string
+	var last time.Time
+	for i := 0; i < 6; i++ {
+		name := string(rune('a' + i))
+		clock.AfterFunc(time.Second+time.Duration(i)*time.Millisecond, func() {
+			if now := clock.Now(); now.Before(last) {
+				order = append(order, "backwards")
+			} else {
+				last = now
+			}
+			order = append(order, name)
+		})
+	}
+	clock.Add(time.Minute)
+	return strings.Join(order, "")
+}
+
+// Ensure that the same seed always produces the same order.
+func TestMock_RandomOrder_Replay(t *testing.T) {
+	want := fireOrder(42, 10*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		if got := fireOrder(42, 10*time.Millisecond); got != want {
+			t.Fatalf("order %q differs from %q with same seed", got, want)
+		}
+	}
+}
+
+// Ensure that timers within epsilon are permuted without time going backwards.
+func TestMock_RandomOrder_Permute(t *testing.T) {
+	orders := make(map[string]bool)
+	for seed := int64(1); seed <= 20; seed++ {
+		order := fireOrder(seed, 10*time.Millisecond)
+		if strings.Contains(order, "backwards") {
+			t.Fatalf("seed %d: time moved backwards: %s", seed, order)
+		} else if len(order) != 6 {
+			t.Fatalf("seed %d: unexpected order: %s", seed, order)
+		}
+		orders[order] = true
+	}
+	if len(orders) < 2 {
+		t.Fatalf("expected different orders, got %v", orders)
+	}
+
+	// Timers further apart than epsilon keep their order.
+	for seed := int64(1); seed <= 5; seed++ {
+		if order := fireOrder(seed, 0); order != "abcdef" {
+			t.Fatalf("seed %d: unexpected order: %s", seed, order)
+		}
+	}
+}
+
+// fakeTB records calls made by Mock.LogSeedOnFailure.
+type fakeTB struct {
+	cleanup func()
+	failed  bool
+	logs    []string
+}
+
+func (tb *fakeTB) Cleanup(f func()) { tb.cleanup = f }
+func (tb *fakeTB) Failed() bool     { return tb.failed }
+func (tb *fakeTB) Logf(format string, args ...interface{}) {
+	tb.logs = append(tb.logs, fmt.Sprintf(format, args...))
+}
+
+// Ensure that the seed is logged only when the test fails.
+func TestMock_LogSeedOnFailure(t *testing.T) {
+	clock := NewMock(WithRandomOrder(0, 0))
+	if clock.Seed() == 0 {
+		t.Fatal("expected a seed to be chosen")
+	}
+
+	tb := &fakeTB{}
+	clock.LogSeedOnFailure(tb)
+	tb.cleanup()
+	if len(tb.logs) != 0 {
+		t.Fatalf("unexpected logs: %v", tb.logs)
+	}
+
+	tb.failed = true
+	tb.cleanup()
+	if want := fmt.Sprintf("seed %d", clock.Seed()); len(tb.logs) != 1 || !strings.Contains(tb.logs[0], want) {
+		t.Fatalf("unexpected logs: %v", tb.logs)
+	}
+}
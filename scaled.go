@@ -0,0 +1,50 @@
+package clock
+
+import (
+	"math"
+	"time"
+)
+
+// Scaled represents a clock whose time passes a constant factor faster or
+// slower than the time of its base clock. It can be used to run simulations
+// faster than real time while still relying on real timers.
+//
+// Timers, tickers, sleepers and contexts of a Scaled clock wait for scaled
+// durations. Changing the factor reschedules them, so that they fire once the
+// scaled time reaches their deadline.
+type Scaled struct {
+	*timeline
+}
+
+// NewScaled returns a clock whose time starts at epoch and then passes factor
+// times as fast as the time of base. If epoch is zero, it starts at the current
+// time of base. It panics if factor is negative or NaN.
+func NewScaled(base Clock, factor float64, epoch time.Time) *Scaled {
+	checkFactor(factor)
+	if epoch.IsZero() {
+		epoch = base.Now()
+	}
+	s := &Scaled{timeline: newTimeline(base, epoch, factor)}
+	s.owner = s
+	return s
+}
+
+// SetFactor changes how fast the time of the clock passes relative to its
+// base from now on. A factor of zero stops the clock. It panics if factor is
+// negative or NaN.
+func (s *Scaled) SetFactor(factor float64) {
+	checkFactor(factor)
+	s.setRate(factor)
+}
+
+// Factor returns how fast the time of the clock passes relative to its base.
+func (s *Scaled) Factor() float64 {
+	return s.getRate()
+}
+
+// checkFactor panics if factor is not a valid scaling factor.
+func checkFactor(factor float64) {
+	if factor < 0 || math.IsNaN(factor) || math.IsInf(factor, 0) {
+		panic("clock: invalid scale factor")
+	}
+}
@@ -0,0 +1,118 @@
+package clock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Ensure that a scaled clock's time and timers run faster than its base.
+func TestScaled(t *testing.T) {
+	base := NewMock()
+	epoch := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	clock := NewScaled(base, 60, epoch)
+
+	if now := clock.Now(); !now.Equal(epoch) {
+		t.Fatalf("unexpected start: %s", now)
+	}
+
+	var n int32
+	timer := clock.Timer(time.Minute)
+	ticker := clock.Ticker(30 * time.Second)
+	clock.AfterFunc(2*time.Minute, func() { atomic.AddInt32(&n, 1) })
+	ctx, cancel := clock.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	base.Add(500 * time.Millisecond)
+	if now := clock.Now(); !now.Equal(epoch.Add(30 * time.Second)) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+	if v := <-ticker.C; !v.Equal(epoch.Add(30 * time.Second)) {
+		t.Fatalf("unexpected tick: %s", v)
+	}
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	base.Add(500 * time.Millisecond)
+	if v := <-timer.C; !v.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("unexpected timer value: %s", v)
+	}
+	if v := <-ticker.C; !v.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("unexpected tick: %s", v)
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+
+	base.Add(time.Second)
+	if got := atomic.LoadInt32(&n); got != 1 {
+		t.Fatalf("expected AfterFunc to run once, got %d", got)
+	}
+	ticker.Stop()
+}
+
+// Ensure that changing the factor reschedules pending timers.
+func TestScaled_SetFactor(t *testing.T) {
+	base := NewMock()
+	clock := NewScaled(base, 60, time.Time{})
+	start := clock.Now()
+	timer := clock.Timer(time.Minute)
+
+	// Half of the timer's duration passes at the original factor.
+	base.Add(500 * time.Millisecond)
+	clock.SetFactor(120)
+	if f := clock.Factor(); f != 120 {
+		t.Fatalf("unexpected factor: %v", f)
+	}
+
+	base.Add(200 * time.Millisecond)
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	base.Add(50 * time.Millisecond)
+	if v := <-timer.C; v.Sub(start) != time.Minute {
+		t.Fatalf("unexpected timer value: %s", v.Sub(start))
+	}
+
+	// A factor of zero stops the clock along with its timers.
+	timer.Reset(time.Second)
+	clock.SetFactor(0)
+	base.Add(time.Hour)
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired while the clock was stopped")
+	default:
+	}
+	if d := clock.Since(start); d != time.Minute {
+		t.Fatalf("unexpected elapsed time: %s", d)
+	}
+}
+
+// Ensure that timers too far away to be represented in base time don't fire.
+func TestScaled_LongTimer(t *testing.T) {
+	base := NewMock()
+	clock := NewScaled(base, 0.0001, time.Time{})
+	var n int32
+	clock.AfterFunc(2000*time.Hour, func() { atomic.AddInt32(&n, 1) })
+
+	done := make(chan struct{})
+	go func() {
+		base.Add(time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Add did not return")
+	}
+	if got := atomic.LoadInt32(&n); got != 0 {
+		t.Fatalf("expected timer not to fire, got %d", got)
+	}
+}
@@ -0,0 +1,55 @@
+package clock
+
+import (
+	"runtime"
+	"time"
+)
+
+// SettleStrategy determines how a Mock waits for the goroutines woken by a
+// timer or ticker to react before it continues to move time forward.
+//
+// The mock clock can't tell when those goroutines have finished reacting, as
+// the runtime doesn't report when a goroutine blocks on a channel. Tests that
+// move time over many ticks can use SettleNone and synchronize with the code
+// under test themselves, e.g. with BlockUntil or by receiving its results.
+type SettleStrategy int
+
+const (
+	// SettleSleep sleeps for a millisecond of real time after each tick,
+	// giving the woken goroutines a chance to run. This is the default
+	// strategy.
+	SettleSleep SettleStrategy = iota
+
+	// SettleNone does not wait at all. Tests using it are responsible for
+	// synchronizing with the goroutines under test themselves.
+	SettleNone
+)
+
+// SetSettleStrategy sets how the mock clock waits for goroutines woken by a
+// timer to react. See SettleStrategy for the available strategies.
+func (m *Mock) SetSettleStrategy(s SettleStrategy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settle = s
+}
+
+// settleGoroutines waits for goroutines woken by the mock clock according to
+// the settle strategy. m.mu MUST NOT be held when this method is called.
+func (m *Mock) settleGoroutines() {
+	m.mu.Lock()
+	s := m.settle
+	yields := m.jitter()
+	m.mu.Unlock()
+
+	// Let the woken goroutines run in a random order when randomizing.
+	for i := 0; i < yields; i++ {
+		runtime.Gosched()
+	}
+
+	if s == SettleSleep {
+		gosched()
+	}
+}
+
+// Sleep momentarily so that other goroutines can process.
+func gosched() { time.Sleep(1 * time.Millisecond) }
@@ -0,0 +1,51 @@
+package clock
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Ensure that the mock clock sleeps after a tick by default.
+func TestMock_SettleSleep(t *testing.T) {
+	var n int32
+	clock := NewMock()
+	if clock.settle != SettleSleep {
+		t.Fatalf("unexpected default settle strategy: %v", clock.settle)
+	}
+
+	clock.AfterFunc(time.Second, func() { atomic.AddInt32(&n, 1) })
+	clock.Add(time.Second)
+	if got := atomic.LoadInt32(&n); got != 1 {
+		t.Fatalf("expected 1, got %d", got)
+	}
+}
+
+// Ensure that a day of one-second ticks passes without sleeping in real time
+// when settling is disabled, and that every tick reaches a consumer that
+// synchronizes with the test itself.
+func TestMock_SettleNone(t *testing.T) {
+	clock := NewMock(WithSettleStrategy(SettleNone), WithTickerPolicy(TickerDeliverAll))
+	ticker := clock.Ticker(time.Second)
+	defer ticker.Stop()
+
+	n := make(chan int)
+	go func() {
+		var count int
+		for range ticker.C {
+			if count++; count == 86400 {
+				n <- count
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	clock.Add(24 * time.Hour)
+	if got := <-n; got != 86400 {
+		t.Fatalf("expected 86400 ticks, got %d", got)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Fatalf("advancing a day took %s", d)
+	}
+}
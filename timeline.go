@@ -0,0 +1,446 @@
+package clock
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// timeline implements a clock whose time is derived from a base clock. Its
+// time runs at rate times the speed of the base clock's time, starting from
+// an anchor. It is shared by the clocks that wrap another clock, such as
+// Scaled.
+//
+// Every timer and ticker of a timeline is woken by a timer on the base clock.
+// When the rate or the current time of the timeline changes, the base timers
+// are rescheduled so that they fire at the new base time.
+type timeline struct {
+	// mu protects all other fields in this struct, and the data that they
+	// point to.
+	mu sync.Mutex
+
+	base    Clock
+	owner   Clock     // clock wrapping the timeline, carried by its contexts
+	anchorB time.Time // base time at the anchor
+	anchorV time.Time // timeline time at the anchor, without a monotonic reading
+	rate    float64   // speed of the timeline relative to the base clock
+
+	timers   map[*virtualTimer]struct{} // registered timers and tickers
+	seq      uint64                     // number of times timers have been scheduled
+	contexts map[*timerCtx]struct{}     // contexts with deadlines that aren't done yet
+}
+
+// newTimeline returns a timeline over base that starts at start.
+func newTimeline(base Clock, start time.Time, rate float64) *timeline {
+	return &timeline{
+		base:     base,
+		anchorB:  base.Now(),
+		anchorV:  start.Round(0),
+		rate:     rate,
+		timers:   make(map[*virtualTimer]struct{}),
+		contexts: make(map[*timerCtx]struct{}),
+	}
+}
+
+// now returns the current time of the timeline. tl.mu MUST be held when this
+// method is called.
+func (tl *timeline) now() time.Time {
+	return tl.anchorV.Add(scale(tl.base.Since(tl.anchorB), tl.rate))
+}
+
+// reanchor moves the anchor to the current time. tl.mu MUST be held when this
+// method is called.
+func (tl *timeline) reanchor() {
+	tl.anchorV, tl.anchorB = tl.now(), tl.base.Now()
+}
+
+// setRate changes the speed of the timeline and reschedules its timers.
+func (tl *timeline) setRate(rate float64) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	if rate == tl.rate {
+		return
+	}
+	tl.reanchor()
+	tl.rate = rate
+	tl.rescheduleAll()
+}
+
+// getRate returns the speed of the timeline.
+func (tl *timeline) getRate() float64 {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return tl.rate
+}
+
+// shift steps the time of the timeline by d, moving its timers and the
+// deadlines of its contexts along with it so that they still expire after the
+// same base durations. tl.mu MUST be held when this method is called.
+func (tl *timeline) shift(d time.Duration) {
+	tl.anchorV = tl.anchorV.Add(d)
+	for vt := range tl.timers {
+		vt.when = vt.when.Add(d)
+	}
+	for ctx := range tl.contexts {
+		ctx.shift(d)
+	}
+}
+
+// advance steps the time of the timeline forward by d, without any base time
+// elapsing. Timers due within d fire in order, before advance returns.
+func (tl *timeline) advance(d time.Duration) {
+	tl.mu.Lock()
+	tl.reanchor()
+	target := tl.anchorV.Add(d)
+	for {
+		vt := tl.nextTimer(target)
+		if vt == nil {
+			break
+		}
+
+		// Move time to the timer, so that it sees the time it was due at.
+		if vt.when.After(tl.anchorV) {
+			tl.anchorV, tl.anchorB = vt.when, tl.base.Now()
+		}
+		fn := tl.expire(vt, tl.anchorV)
+		if fn != nil {
+			tl.mu.Unlock()
+			fn()
+			tl.mu.Lock()
+		}
+	}
+	tl.anchorV, tl.anchorB = target, tl.base.Now()
+	tl.rescheduleAll()
+	tl.mu.Unlock()
+}
+
+// nextTimer returns the earliest timer due no later than max, or nil if there
+// is none. tl.mu MUST be held when this method is called.
+func (tl *timeline) nextTimer(max time.Time) *virtualTimer {
+	var next *virtualTimer
+	for vt := range tl.timers {
+		if vt.when.After(max) {
+			continue
+		} else if next == nil || vt.before(next) {
+			next = vt
+		}
+	}
+	return next
+}
+
+// rescheduleAll reschedules the base timers of all registered timers. tl.mu
+// MUST be held when this method is called.
+func (tl *timeline) rescheduleAll() {
+	a := make([]*virtualTimer, 0, len(tl.timers))
+	for vt := range tl.timers {
+		a = append(a, vt)
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].before(a[j]) })
+	for _, vt := range a {
+		tl.schedule(vt)
+	}
+}
+
+// register adds vt to the registered timers and schedules it. tl.mu MUST be
+// held when this method is called.
+func (tl *timeline) register(vt *virtualTimer) {
+	tl.seq++
+	vt.seq = tl.seq
+	vt.active = true
+	tl.timers[vt] = struct{}{}
+	tl.schedule(vt)
+}
+
+// unregister stops vt and removes it from the registered timers. tl.mu MUST be
+// held when this method is called.
+func (tl *timeline) unregister(vt *virtualTimer) {
+	vt.active = false
+	vt.gen++
+	if vt.base != nil {
+		vt.base.Stop()
+		vt.base = nil
+	}
+	delete(tl.timers, vt)
+}
+
+// schedule starts a base timer that fires when vt is due. Any previous base
+// timer of vt is stopped. tl.mu MUST be held when this method is called.
+func (tl *timeline) schedule(vt *virtualTimer) {
+	vt.gen++
+	if vt.base != nil {
+		vt.base.Stop()
+		vt.base = nil
+	}
+	if tl.rate == 0 {
+		return // time is standing still
+	}
+
+	// Round up so that the timeline has reached vt.when once d has elapsed.
+	// Durations too long to represent, e.g. at a very slow rate, are clamped
+	// so that the timer waits as long as possible.
+	var d time.Duration
+	if rem := vt.when.Sub(tl.now()); rem > 0 {
+		if f := math.Ceil(float64(rem) / tl.rate); f >= math.MaxInt64 {
+			d = math.MaxInt64
+		} else if d = time.Duration(f); scale(d, tl.rate) < rem {
+			d++
+		}
+	}
+	gen := vt.gen
+	vt.base = tl.base.AfterFunc(d, func() { tl.fire(vt, gen) })
+}
+
+// fire is called by the base timer of vt.
+func (tl *timeline) fire(vt *virtualTimer, gen uint64) {
+	tl.mu.Lock()
+	if !vt.active || vt.gen != gen {
+		tl.mu.Unlock()
+		return // stopped or rescheduled since
+	}
+	vt.base = nil
+
+	now := tl.now()
+	if vt.when.After(now) {
+		// Woken before the timer is due, e.g. due to rounding.
+		tl.schedule(vt)
+		tl.mu.Unlock()
+		return
+	}
+	fn := tl.expire(vt, now)
+	tl.mu.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+}
+
+// expire delivers a tick of vt at now, then re-arms tickers and unregisters
+// timers. Returns the function to run for AfterFunc timers. tl.mu MUST be held
+// when this method is called.
+func (tl *timeline) expire(vt *virtualTimer, now time.Time) func() {
+	if vt.fn == nil {
+		select {
+		case vt.c <- now:
+		default:
+		}
+	}
+
+	if vt.period > 0 {
+		// Skip ticks that the timeline has already passed.
+		n := now.Sub(vt.when)/vt.period + 1
+		vt.when = vt.when.Add(n * vt.period)
+		tl.seq++
+		vt.seq = tl.seq
+		tl.schedule(vt)
+	} else {
+		tl.unregister(vt)
+	}
+	return vt.fn
+}
+
+// drain empties c, if it isn't nil. Returns true if a value was removed.
+func drain(c chan time.Time) bool {
+	if c == nil {
+		return false
+	}
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// scale returns d multiplied by rate.
+func scale(d time.Duration, rate float64) time.Duration {
+	if rate == 1 {
+		return d
+	}
+	return time.Duration(float64(d) * rate)
+}
+
+// virtualTimer is a timer or ticker registered on a timeline.
+type virtualTimer struct {
+	tl     *timeline
+	when   time.Time      // time the timer is next due
+	period time.Duration  // time between ticks, zero for one-shot timers
+	c      chan time.Time // channel ticks are sent on, nil for AfterFunc
+	fn     func()         // function to run, nil for channel-based timers
+
+	base   *Timer // base clock timer waking the timer, if scheduled
+	gen    uint64 // incremented each time the base timer is replaced
+	seq    uint64 // order the timer was scheduled in
+	active bool   // true while registered
+}
+
+// before returns true if vt is due before other.
+func (vt *virtualTimer) before(other *virtualTimer) bool {
+	if !vt.when.Equal(other.when) {
+		return vt.when.Before(other.when)
+	}
+	return vt.seq < other.seq
+}
+
+// Stop stops the timer. Returns true if the timer had not expired yet.
+func (vt *virtualTimer) Stop() bool {
+	vt.tl.mu.Lock()
+	defer vt.tl.mu.Unlock()
+	registered := vt.active
+	if drain(vt.c) {
+		// An unreceived value means the timer hasn't expired as far as the
+		// receiver can tell.
+		registered = true
+	}
+	vt.tl.unregister(vt)
+	return registered
+}
+
+// Reset changes the timer to expire after d, or changes the period of a
+// ticker to d. Returns true if the timer had not expired yet.
+func (vt *virtualTimer) Reset(d time.Duration) bool {
+	if vt.period > 0 && d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+
+	vt.tl.mu.Lock()
+	defer vt.tl.mu.Unlock()
+	registered := vt.active
+	if drain(vt.c) {
+		registered = true
+	}
+	vt.tl.unregister(vt)
+	if vt.period > 0 {
+		vt.period = d
+	}
+	vt.when = vt.tl.now().Add(d)
+	vt.tl.register(vt)
+	return registered
+}
+
+// newTimer creates and registers a timer that fires after d, with a channel
+// if fn is nil.
+func (tl *timeline) newTimer(d time.Duration, fn func()) *virtualTimer {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	vt := &virtualTimer{tl: tl, when: tl.now().Add(d), fn: fn}
+	if fn == nil {
+		vt.c = make(chan time.Time, 1)
+	}
+	tl.register(vt)
+	return vt
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func (tl *timeline) After(d time.Duration) <-chan time.Time {
+	return tl.Timer(d).C
+}
+
+// AfterFunc waits for the duration to elapse and then calls f in its own
+// goroutine. A Timer is returned that can be stopped. If the timer becomes
+// due because the time is stepped forward, e.g. by Hybrid.Advance, f is called
+// on the goroutine stepping the time instead.
+func (tl *timeline) AfterFunc(d time.Duration, f func()) *Timer {
+	return &Timer{impl: tl.newTimer(d, f)}
+}
+
+// Now returns the current time.
+func (tl *timeline) Now() time.Time {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return tl.now()
+}
+
+// Since returns the time elapsed since t.
+func (tl *timeline) Since(t time.Time) time.Duration { return tl.Now().Sub(t) }
+
+// Until returns the duration until t.
+func (tl *timeline) Until(t time.Time) time.Duration { return t.Sub(tl.Now()) }
+
+// Sleep pauses the goroutine for the given duration.
+func (tl *timeline) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-tl.After(d)
+}
+
+// Tick is a convenience function for Ticker(). It returns a ticker channel
+// that cannot be stopped.
+func (tl *timeline) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	return tl.Ticker(d).C
+}
+
+// Ticker returns a new Ticker that ticks every d. It panics if d is not
+// positive.
+func (tl *timeline) Ticker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	vt := &virtualTimer{tl: tl, when: tl.now().Add(d), period: d, c: make(chan time.Time, 1)}
+	tl.register(vt)
+	return &Ticker{C: vt.c, impl: vt}
+}
+
+// Timer returns a new Timer that sends the current time on its channel after
+// d.
+func (tl *timeline) Timer(d time.Duration) *Timer {
+	vt := tl.newTimer(d, nil)
+	return &Timer{C: vt.c, impl: vt}
+}
+
+// WithDeadline returns a copy of parent that is cancelled once the timeline
+// reaches d.
+func (tl *timeline) WithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc) {
+	return tl.withDeadline(parent, d, nil)
+}
+
+// WithTimeout returns WithDeadline(parent, Now().Add(t)).
+func (tl *timeline) WithTimeout(parent context.Context, t time.Duration) (context.Context, context.CancelFunc) {
+	return tl.WithDeadline(parent, tl.Now().Add(t))
+}
+
+// WithDeadlineCause is like WithDeadline but sets the cause of the returned
+// context to cause when the deadline is exceeded.
+func (tl *timeline) WithDeadlineCause(parent context.Context, d time.Time, cause error) (context.Context, context.CancelFunc) {
+	return tl.withDeadline(parent, d, cause)
+}
+
+// WithTimeoutCause is like WithTimeout but sets the cause of the returned
+// context to cause when the timeout expires.
+func (tl *timeline) WithTimeoutCause(parent context.Context, t time.Duration, cause error) (context.Context, context.CancelFunc) {
+	return tl.WithDeadlineCause(parent, tl.Now().Add(t), cause)
+}
+
+// withDeadline creates a context that is cancelled once the timeline reaches
+// d, and registers it until it is done.
+func (tl *timeline) withDeadline(parent context.Context, d time.Time, cause error) (context.Context, context.CancelFunc) {
+	ctx, cancel := withDeadline(tl.owner, parent, d, cause, tl.AfterFunc)
+	tl.addContext(ctx)
+	return ctx, cancel
+}
+
+// addContext registers ctx in tl.contexts unless it is already done.
+func (tl *timeline) addContext(ctx *timerCtx) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	tl.contexts[ctx] = struct{}{}
+}
+
+// removeContext removes ctx from tl.contexts.
+func (tl *timeline) removeContext(ctx *timerCtx) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	delete(tl.contexts, ctx)
+}